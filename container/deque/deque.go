@@ -0,0 +1,206 @@
+package deque
+
+import (
+	"errors"
+	"iter"
+)
+
+// ErrFull 有界且不允许覆盖的 Deque 已满
+var ErrFull = errors.New("deque is full")
+
+// Deque 是基于环形缓冲区的双端队列，两端的入队、出队均为 O(1)
+// 无界模式下按需扩容；有界模式下容量固定，可选择满时覆盖最旧的元素（适合"最近 N 个样本"窗口）
+type Deque[T any] struct {
+	buf       []T
+	head      int // 队首在 buf 中的下标
+	n         int // 元素个数
+	bounded   bool
+	overwrite bool
+}
+
+// New 创建一个无界的 Deque
+func New[T any]() *Deque[T] {
+	return &Deque[T]{}
+}
+
+// NewBounded 创建一个容量固定的 Deque
+// overwrite 为 true 时，满后 PushBack 覆盖队首（最旧）元素，PushFront 覆盖队尾元素；否则返回 ErrFull
+func NewBounded[T any](capacity int, overwrite bool) (*Deque[T], error) {
+	if capacity <= 0 {
+		return nil, errors.New("capacity must be positive")
+	}
+	return &Deque[T]{
+		buf:       make([]T, capacity),
+		bounded:   true,
+		overwrite: overwrite,
+	}, nil
+}
+
+// Len 返回元素个数
+func (d *Deque[T]) Len() int { return d.n }
+
+// Cap 返回当前容量
+func (d *Deque[T]) Cap() int { return len(d.buf) }
+
+// Full 判断有界 Deque 是否已满，无界 Deque 永远返回 false
+func (d *Deque[T]) Full() bool { return d.bounded && d.n == len(d.buf) }
+
+// idx 把逻辑下标转换为 buf 中的下标
+func (d *Deque[T]) idx(i int) int {
+	i += d.head
+	if i >= len(d.buf) {
+		i -= len(d.buf)
+	}
+	return i
+}
+
+// grow 容量翻倍，并把元素整理到从 0 开始的连续位置
+func (d *Deque[T]) grow() {
+	buf := make([]T, max(8, 2*len(d.buf)))
+	d.copyTo(buf)
+	d.buf = buf
+	d.head = 0
+}
+
+// copyTo 按逻辑顺序把元素复制到 dst
+func (d *Deque[T]) copyTo(dst []T) {
+	if d.head+d.n <= len(d.buf) {
+		copy(dst, d.buf[d.head:d.head+d.n])
+		return
+	}
+	k := copy(dst, d.buf[d.head:])
+	copy(dst[k:], d.buf[:d.n-k])
+}
+
+// PushBack 在队尾添加元素
+// 时间复杂度: 均摊 O(1)
+func (d *Deque[T]) PushBack(v T) error {
+	if d.n == len(d.buf) {
+		switch {
+		case !d.bounded:
+			d.grow()
+		case d.overwrite:
+			d.PopFront()
+		default:
+			return ErrFull
+		}
+	}
+	d.buf[d.idx(d.n)] = v
+	d.n++
+	return nil
+}
+
+// PushFront 在队首添加元素
+// 时间复杂度: 均摊 O(1)
+func (d *Deque[T]) PushFront(v T) error {
+	if d.n == len(d.buf) {
+		switch {
+		case !d.bounded:
+			d.grow()
+		case d.overwrite:
+			d.PopBack()
+		default:
+			return ErrFull
+		}
+	}
+	d.head--
+	if d.head < 0 {
+		d.head += len(d.buf)
+	}
+	d.buf[d.head] = v
+	d.n++
+	return nil
+}
+
+// PopFront 移除并返回队首元素
+// 时间复杂度: O(1)
+func (d *Deque[T]) PopFront() (T, bool) {
+	var zero T
+	if d.n == 0 {
+		return zero, false
+	}
+	v := d.buf[d.head]
+	d.buf[d.head] = zero // 清零，避免内存保持
+	d.head = d.idx(1)
+	d.n--
+	return v, true
+}
+
+// PopBack 移除并返回队尾元素
+// 时间复杂度: O(1)
+func (d *Deque[T]) PopBack() (T, bool) {
+	var zero T
+	if d.n == 0 {
+		return zero, false
+	}
+	i := d.idx(d.n - 1)
+	v := d.buf[i]
+	d.buf[i] = zero
+	d.n--
+	return v, true
+}
+
+// Front 返回队首元素但不移除
+func (d *Deque[T]) Front() (T, bool) { return d.At(0) }
+
+// Back 返回队尾元素但不移除
+func (d *Deque[T]) Back() (T, bool) { return d.At(-1) }
+
+// At 返回第 i 个元素，支持负索引，越界返回 false
+// 时间复杂度: O(1)
+func (d *Deque[T]) At(i int) (T, bool) {
+	if i < 0 {
+		i += d.n
+	}
+	if i < 0 || i >= d.n {
+		var zero T
+		return zero, false
+	}
+	return d.buf[d.idx(i)], true
+}
+
+// Clear 清空 Deque，保留容量
+func (d *Deque[T]) Clear() {
+	clear(d.buf)
+	d.head, d.n = 0, 0
+}
+
+// ToSlice 按从队首到队尾的顺序返回元素副本
+func (d *Deque[T]) ToSlice() []T {
+	out := make([]T, d.n)
+	d.copyTo(out)
+	return out
+}
+
+// All 返回从队首到队尾遍历 (下标, 元素) 的迭代器
+func (d *Deque[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < d.n; i++ {
+			if !yield(i, d.buf[d.idx(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// Values 返回从队首到队尾遍历元素的迭代器
+func (d *Deque[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < d.n; i++ {
+			if !yield(d.buf[d.idx(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// Backward 返回从队尾到队首遍历 (下标, 元素) 的迭代器
+func (d *Deque[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := d.n - 1; i >= 0; i-- {
+			if !yield(i, d.buf[d.idx(i)]) {
+				return
+			}
+		}
+	}
+}
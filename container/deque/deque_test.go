@@ -0,0 +1,96 @@
+package deque
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestDeque(t *testing.T) {
+	d := New[int]()
+	for i := 0; i < 20; i++ {
+		d.PushBack(i)
+		d.PushFront(-i)
+	}
+	if d.Len() != 40 {
+		t.Fatalf("Len = %d, want 40", d.Len())
+	}
+	if v, _ := d.Front(); v != -19 {
+		t.Errorf("Front = %d, want -19", v)
+	}
+	if v, _ := d.PopBack(); v != 19 {
+		t.Errorf("PopBack = %d, want 19", v)
+	}
+	if v, _ := d.At(20); v != 0 {
+		t.Errorf("At(20) = %d, want 0", v)
+	}
+	for i, v := range d.All() {
+		want := i - 19 // 前 20 个来自 PushFront：-19..0
+		if i >= 20 {
+			want = i - 20 // 后 19 个来自 PushBack：0..18
+		}
+		if v != want {
+			t.Fatalf("At(%d) = %d, want %d", i, v, want)
+		}
+	}
+}
+
+func TestDequeBoundedOverwrite(t *testing.T) {
+	d, _ := NewBounded[int](3, true)
+	for i := 1; i <= 5; i++ {
+		d.PushBack(i)
+	}
+	got := d.ToSlice()
+	if len(got) != 3 || got[0] != 3 || got[2] != 5 {
+		t.Errorf("window = %v, want [3 4 5]", got)
+	}
+
+	strict, _ := NewBounded[int](1, false)
+	strict.PushBack(1)
+	if err := strict.PushFront(2); err != ErrFull {
+		t.Errorf("PushFront on full deque = %v, want ErrFull", err)
+	}
+}
+
+func TestSPSC(t *testing.T) {
+	q, _ := NewSPSC[int](100)
+	if q.Cap() != 128 {
+		t.Fatalf("Cap = %d, want 128", q.Cap())
+	}
+	const n = 10000
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < n; {
+			if q.TryPush(i) {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+	for want := 0; want < n; {
+		if v, ok := q.TryPop(); ok {
+			if v != want {
+				t.Fatalf("TryPop = %d, want %d", v, want)
+			}
+			want++
+		} else {
+			runtime.Gosched()
+		}
+	}
+	<-done
+}
+
+func TestSPSCLenClamped(t *testing.T) {
+	q, _ := NewSPSC[int](4)
+	// 模拟 Len 并发读到的不一致的 head/tail
+	q.head.Store(5)
+	q.tail.Store(3)
+	if got := q.Len(); got != 0 {
+		t.Errorf("Len with head > tail = %d, want 0", got)
+	}
+	q.tail.Store(5 + 10)
+	if got := q.Len(); got != q.Cap() {
+		t.Errorf("Len with tail-head > cap = %d, want %d", got, q.Cap())
+	}
+}
@@ -0,0 +1,64 @@
+package deque
+
+import (
+	"errors"
+	"math/bits"
+	"sync/atomic"
+)
+
+// SPSC 是单生产者单消费者的无锁环形队列
+// 同一时刻只能有一个 goroutine 调用 TryPush、一个 goroutine 调用 TryPop
+type SPSC[T any] struct {
+	buf  []T
+	mask uint64
+	_    [64]byte      // 填充，避免 head 与 tail 伪共享
+	head atomic.Uint64 // 下一个读取位置，只由消费者写
+	_    [56]byte
+	tail atomic.Uint64 // 下一个写入位置，只由生产者写
+}
+
+// NewSPSC 创建一个 SPSC 队列，容量向上取整为 2 的幂
+func NewSPSC[T any](capacity int) (*SPSC[T], error) {
+	if capacity <= 0 {
+		return nil, errors.New("capacity must be positive")
+	}
+	size := 1 << bits.Len(uint(capacity-1))
+	return &SPSC[T]{
+		buf:  make([]T, size),
+		mask: uint64(size - 1),
+	}, nil
+}
+
+// Cap 返回容量
+func (q *SPSC[T]) Cap() int { return len(q.buf) }
+
+// Len 返回当前元素个数（并发调用时为近似值）
+// head 与 tail 分两次读取，并发时差值可能为负或超过容量，因此截断到 [0, Cap()]
+func (q *SPSC[T]) Len() int {
+	n := int64(q.tail.Load() - q.head.Load())
+	return int(max(0, min(n, int64(len(q.buf)))))
+}
+
+// TryPush 入队，队列已满时返回 false。只能由生产者调用
+func (q *SPSC[T]) TryPush(v T) bool {
+	tail := q.tail.Load()
+	if tail-q.head.Load() == uint64(len(q.buf)) {
+		return false
+	}
+	q.buf[tail&q.mask] = v
+	q.tail.Store(tail + 1) // 发布：消费者读到新的 tail 后才会读取该槽位
+	return true
+}
+
+// TryPop 出队，队列为空时返回 false。只能由消费者调用
+func (q *SPSC[T]) TryPop() (T, bool) {
+	var zero T
+	head := q.head.Load()
+	if head == q.tail.Load() {
+		return zero, false
+	}
+	v := q.buf[head&q.mask]
+	q.buf[head&q.mask] = zero // 清零，避免内存保持
+	q.head.Store(head + 1)    // 归还槽位
+	return v, true
+}
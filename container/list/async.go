@@ -0,0 +1,216 @@
+package list
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"sync"
+)
+
+// ---------- 可返回错误、可取消的并发遍历 ----------
+
+// ErrorMode 决定并发遍历遇到错误时的行为。
+type ErrorMode int
+
+const (
+	// StopOnFirstError 遇到第一个错误即取消 ctx、停止派发，返回该错误。
+	StopOnFirstError ErrorMode = iota
+	// CollectErrors 继续处理所有元素，返回按下标排序后合并的全部错误。
+	CollectErrors
+)
+
+// IndexError 记录出错元素的下标。
+type IndexError struct {
+	Index int
+	Err   error
+}
+
+func (e *IndexError) Error() string { return fmt.Sprintf("index %d: %v", e.Index, e.Err) }
+
+func (e *IndexError) Unwrap() error { return e.Err }
+
+// PanicError 由回调中的 panic 转换而来，Stack 为 panic 时的调用栈。
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string { return fmt.Sprintf("panic: %v", e.Value) }
+
+// safeCall 调用 fn，并把 panic 转换为 *PanicError。
+func safeCall(ctx context.Context, i int, fn func(ctx context.Context, i int) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(ctx, i)
+}
+
+// runAsync 以最多 maxGoroutines 个 goroutine 对下标 [0, n) 执行 fn，返回时所有已派发的任务均已结束。
+func runAsync(
+	ctx context.Context,
+	n int,
+	maxGoroutines int,
+	mode ErrorMode,
+	fn func(ctx context.Context, i int) error,
+) error {
+	if maxGoroutines <= 0 {
+		maxGoroutines = runtime.GOMAXPROCS(0)
+	}
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, maxGoroutines)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []*IndexError
+	)
+
+dispatch:
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case sem <- struct{}{}:
+		}
+		// 两个分支同时就绪时 select 随机选择，这里再检查一次
+		if ctx.Err() != nil {
+			<-sem
+			break dispatch
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := safeCall(ctx, i, fn); err != nil {
+				mu.Lock()
+				errs = append(errs, &IndexError{Index: i, Err: err})
+				mu.Unlock()
+				if mode == StopOnFirstError {
+					cancel()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(errs) == 0 {
+		return parent.Err()
+	}
+	if mode == StopOnFirstError {
+		return errs[0]
+	}
+	slices.SortFunc(errs, func(a, b *IndexError) int { return cmp.Compare(a.Index, b.Index) })
+	joined := make([]error, 0, len(errs)+1)
+	for _, e := range errs {
+		joined = append(joined, e)
+	}
+	if err := parent.Err(); err != nil {
+		joined = append(joined, err)
+	}
+	return errors.Join(joined...)
+}
+
+// ForEachAsyncErr 并发只读遍历 List，回调接收 ctx 并可返回错误，panic 会被转换为 *PanicError。
+// 返回的错误中，单个元素的错误以 *IndexError 包装。
+func (l *List[T]) ForEachAsyncErr(
+	ctx context.Context,
+	maxGoroutines int,
+	mode ErrorMode,
+	fn func(ctx context.Context, v T, i int) error,
+) error {
+	data := l.ToSlice() // 拷贝，避免 goroutine 里读到被外部改动的值
+	return runAsync(ctx, len(data), maxGoroutines, mode, func(ctx context.Context, i int) error {
+		return fn(ctx, data[i], i)
+	})
+}
+
+// MapAsyncErr 并发映射 List，返回与原 List 等长的新 List。
+// 出错时同样返回结果：已成功的下标保存映射结果，其余下标为零值。
+func MapAsyncErr[T any, R any](
+	ctx context.Context,
+	l *List[T],
+	maxGoroutines int,
+	mode ErrorMode,
+	fn func(ctx context.Context, v T, i int) (R, error),
+) (*List[R], error) {
+	data := l.ToSlice()
+	out := make([]R, len(data))
+	err := runAsync(ctx, len(data), maxGoroutines, mode, func(ctx context.Context, i int) error {
+		r, err := fn(ctx, data[i], i)
+		if err != nil {
+			return err
+		}
+		out[i] = r
+		return nil
+	})
+	return &List[R]{data: out}, err
+}
+
+// FilterAsync 并发判断元素是否保留，结果保持原顺序。
+// 出错时返回已成功判断且需保留的元素。
+func (l *List[T]) FilterAsync(
+	ctx context.Context,
+	maxGoroutines int,
+	mode ErrorMode,
+	pred func(ctx context.Context, v T, i int) (bool, error),
+) (*List[T], error) {
+	data := l.ToSlice()
+	keep := make([]bool, len(data))
+	err := runAsync(ctx, len(data), maxGoroutines, mode, func(ctx context.Context, i int) error {
+		ok, err := pred(ctx, data[i], i)
+		if err != nil {
+			return err
+		}
+		keep[i] = ok
+		return nil
+	})
+	out := make([]T, 0, len(data))
+	for i, x := range data {
+		if keep[i] {
+			out = append(out, x)
+		}
+	}
+	return &List[T]{data: out}, err
+}
+
+// ReduceAsync 并发计算每个元素的中间结果，再按下标顺序用 combine 聚合，结果与并发度无关。
+// 出错时返回已成功元素的聚合结果。
+func ReduceAsync[T any, R any, A any](
+	ctx context.Context,
+	l *List[T],
+	maxGoroutines int,
+	mode ErrorMode,
+	init A,
+	fn func(ctx context.Context, v T, i int) (R, error),
+	combine func(acc A, r R, i int) A,
+) (A, error) {
+	data := l.ToSlice()
+	results := make([]R, len(data))
+	done := make([]bool, len(data))
+	err := runAsync(ctx, len(data), maxGoroutines, mode, func(ctx context.Context, i int) error {
+		r, err := fn(ctx, data[i], i)
+		if err != nil {
+			return err
+		}
+		results[i], done[i] = r, true
+		return nil
+	})
+	acc := init
+	for i, r := range results {
+		if done[i] {
+			acc = combine(acc, r, i)
+		}
+	}
+	return acc, err
+}
@@ -0,0 +1,71 @@
+package list
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// ---------- 序列化（JSON / gob / text） ----------
+
+// MarshalJSON 将 List 编码为 JSON 数组。
+func (l *List[T]) MarshalJSON() ([]byte, error) {
+	if l.data == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l.data)
+}
+
+// UnmarshalJSON 从 JSON 数组解码，覆盖 List 原有内容。
+func (l *List[T]) UnmarshalJSON(b []byte) error {
+	xs := make([]T, 0)
+	if err := json.Unmarshal(b, &xs); err != nil {
+		return err
+	}
+	if xs == nil { // JSON null
+		xs = make([]T, 0)
+	}
+	l.data = xs
+	return nil
+}
+
+// MarshalText 实现 encoding.TextMarshaler，文本格式与 MarshalJSON 相同。
+func (l *List[T]) MarshalText() ([]byte, error) { return l.MarshalJSON() }
+
+// UnmarshalText 实现 encoding.TextUnmarshaler，文本格式与 UnmarshalJSON 相同。
+func (l *List[T]) UnmarshalText(b []byte) error { return l.UnmarshalJSON(b) }
+
+// GobEncode 实现 gob.GobEncoder。
+func (l *List[T]) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(l.data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode 实现 gob.GobDecoder，覆盖 List 原有内容。
+func (l *List[T]) GobDecode(b []byte) error {
+	xs := make([]T, 0)
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&xs); err != nil {
+		return err
+	}
+	l.data = xs
+	return nil
+}
+
+// MarshalJSON 将 SyncList 的快照编码为 JSON 数组。
+func (s *SyncList[T]) MarshalJSON() ([]byte, error) { return s.view().MarshalJSON() }
+
+// UnmarshalJSON 从 JSON 数组解码，覆盖 SyncList 原有内容。
+func (s *SyncList[T]) UnmarshalJSON(b []byte) error {
+	l := New[T]()
+	if err := l.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.l = l
+	s.snap.Store(nil)
+	return nil
+}
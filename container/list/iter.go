@@ -0,0 +1,179 @@
+package list
+
+import "iter"
+
+// ---------- 迭代器（Go 1.23 range-over-func） ----------
+
+// All 返回按下标顺序遍历 (下标, 元素) 的迭代器。
+func (l *List[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < len(l.data); i++ {
+			if !yield(i, l.data[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Values 返回按下标顺序遍历元素的迭代器。
+func (l *List[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < len(l.data); i++ {
+			if !yield(l.data[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Backward 返回从后往前遍历 (下标, 元素) 的迭代器。
+func (l *List[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := len(l.data) - 1; i >= 0; i-- {
+			if !yield(i, l.data[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Collect 将迭代器中的所有元素收集为新的 List。
+func Collect[T any](seq iter.Seq[T]) *List[T] {
+	l := New[T]()
+	for v := range seq {
+		l.data = append(l.data, v)
+	}
+	return l
+}
+
+// ---------- 惰性适配器（按需求值，不产生中间 List） ----------
+
+// MapSeq 惰性映射：返回对每个元素应用 fn 后的迭代器。
+func MapSeq[T any, R any](seq iter.Seq[T], fn func(v T) R) iter.Seq[R] {
+	return func(yield func(R) bool) {
+		for v := range seq {
+			if !yield(fn(v)) {
+				return
+			}
+		}
+	}
+}
+
+// FilterSeq 惰性过滤：只保留满足条件的元素。
+func FilterSeq[T any](seq iter.Seq[T], pred func(v T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if pred(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// TakeSeq 惰性截取前 n 个元素。
+func TakeSeq[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		count := 0
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+			count++
+			if count >= n {
+				return
+			}
+		}
+	}
+}
+
+// SkipSeq 惰性跳过前 n 个元素。
+func SkipSeq[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		count := 0
+		for v := range seq {
+			if count < n {
+				count++
+				continue
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// ZipSeq 将两个迭代器按位置配对，较短者结束时停止。
+func ZipSeq[A any, B any](a iter.Seq[A], b iter.Seq[B]) iter.Seq2[A, B] {
+	return func(yield func(A, B) bool) {
+		nextB, stop := iter.Pull(b)
+		defer stop()
+		for va := range a {
+			vb, ok := nextB()
+			if !ok || !yield(va, vb) {
+				return
+			}
+		}
+	}
+}
+
+// ChunkSeq 将元素按每 size 个分块，最后一块可能不足 size 个。每次产出新的切片。
+func ChunkSeq[T any](seq iter.Seq[T], size int) iter.Seq[[]T] {
+	if size <= 0 {
+		panic("chunk size must be positive")
+	}
+	return func(yield func([]T) bool) {
+		chunk := make([]T, 0, size)
+		for v := range seq {
+			chunk = append(chunk, v)
+			if len(chunk) == size {
+				if !yield(chunk) {
+					return
+				}
+				chunk = make([]T, 0, size)
+			}
+		}
+		if len(chunk) > 0 {
+			yield(chunk)
+		}
+	}
+}
+
+// WindowSeq 产出长度为 size 的滑动窗口（步长为 1）。元素不足 size 个时不产出。每次产出新的切片。
+func WindowSeq[T any](seq iter.Seq[T], size int) iter.Seq[[]T] {
+	if size <= 0 {
+		panic("window size must be positive")
+	}
+	return func(yield func([]T) bool) {
+		buf := make([]T, 0, size)
+		for v := range seq {
+			if len(buf) == size {
+				copy(buf, buf[1:])
+				buf = buf[:size-1]
+			}
+			buf = append(buf, v)
+			if len(buf) == size {
+				w := make([]T, size)
+				copy(w, buf)
+				if !yield(w) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// EnumerateSeq 为元素附加从 0 开始的序号。
+func EnumerateSeq[T any](seq iter.Seq[T]) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		for v := range seq {
+			if !yield(i, v) {
+				return
+			}
+			i++
+		}
+	}
+}
@@ -1,7 +1,15 @@
 package list
 
 import (
+    "bytes"
+    "context"
+    "encoding/gob"
+    "encoding/json"
+    "errors"
+    "runtime"
+    "sync"
     "testing"
+    "time"
 )
 
 func TestListBasic(t *testing.T) {
@@ -17,4 +25,359 @@ func TestListBasic(t *testing.T) {
     if v := l.Get(1); v != 42 {
         t.Errorf("Set/Get(1) = %d, want 42", v)
     }
-}
\ No newline at end of file
+}
+func TestListIter(t *testing.T) {
+    l := From([]int{1, 2, 3, 4, 5, 6})
+
+    var back []int
+    for _, v := range l.Backward() {
+        back = append(back, v)
+    }
+    if back[0] != 6 || back[5] != 1 {
+        t.Errorf("Backward = %v", back)
+    }
+
+    // 惰性链：跳过 1 个，取偶数，乘 10，取前 2 个
+    seq := TakeSeq(MapSeq(FilterSeq(SkipSeq(l.Values(), 1), func(v int) bool { return v%2 == 0 }), func(v int) int { return v * 10 }), 2)
+    got := Collect(seq).ToSlice()
+    if len(got) != 2 || got[0] != 20 || got[1] != 40 {
+        t.Errorf("lazy chain = %v, want [20 40]", got)
+    }
+
+    var windows [][]int
+    for w := range WindowSeq(l.Values(), 5) {
+        windows = append(windows, w)
+    }
+    if len(windows) != 2 || windows[1][0] != 2 || windows[1][4] != 6 {
+        t.Errorf("WindowSeq = %v", windows)
+    }
+
+    var chunks [][]int
+    for c := range ChunkSeq(l.Values(), 4) {
+        chunks = append(chunks, c)
+    }
+    if len(chunks) != 2 || len(chunks[1]) != 2 {
+        t.Errorf("ChunkSeq = %v", chunks)
+    }
+
+    n := 0
+    for a, b := range ZipSeq(l.Values(), From([]string{"a", "b"}).Values()) {
+        if a != n+1 || b != string(rune('a'+n)) {
+            t.Errorf("ZipSeq pair %d = %d, %s", n, a, b)
+        }
+        n++
+    }
+    if n != 2 {
+        t.Errorf("ZipSeq yielded %d pairs, want 2", n)
+    }
+}
+
+func TestSyncList(t *testing.T) {
+    s := NewSync[int]()
+    eq := func(a, b int) bool { return a == b }
+
+    var wg sync.WaitGroup
+    for g := 0; g < 8; g++ {
+        wg.Add(1)
+        go func(g int) {
+            defer wg.Done()
+            for i := 0; i < 100; i++ {
+                s.AppendIfAbsent(i, eq)
+                s.ForEach(func(v, i int) {})
+                if g == 0 {
+                    s.Update(0, func(v int) int { return v })
+                }
+            }
+        }(g)
+    }
+    wg.Wait()
+
+    if s.Len() != 100 {
+        t.Fatalf("Len = %d, want 100", s.Len())
+    }
+    snap := s.Snapshot()
+    s.Update(-1, func(v int) int { return v * 10 })
+    if snap.Get(-1) != 99 || s.Get(-1) != 990 {
+        t.Errorf("snapshot = %d, live = %d, want 99, 990", snap.Get(-1), s.Get(-1))
+    }
+
+    // 快照是私有副本：修改它不影响 SyncList 和其他快照
+    a, b := s.Snapshot(), s.Snapshot()
+    a.Append(-1)
+    a.Set(0, -2)
+    if b.Len() != 100 || s.Len() != 100 || b.Get(0) == -2 || s.Get(0) == -2 || s.ToSlice()[0] == -2 {
+        t.Errorf("mutating a snapshot leaked into other readers")
+    }
+}
+
+func TestMapAsyncErrStopOnFirst(t *testing.T) {
+    l := From([]int{1, 2, 3, 4, 5})
+    boom := errors.New("boom")
+    // 单 goroutine 时派发顺序确定，下标 2 出错后不再派发后续元素
+    out, err := MapAsyncErr(context.Background(), l, 1, StopOnFirstError, func(ctx context.Context, v, i int) (int, error) {
+        if i == 2 {
+            return 0, boom
+        }
+        return v * 10, nil
+    })
+    var ie *IndexError
+    if !errors.As(err, &ie) || ie.Index != 2 || !errors.Is(err, boom) {
+        t.Fatalf("err = %v, want index 2 boom", err)
+    }
+    want := []int{10, 20, 0, 0, 0}
+    for i, v := range out.ToSlice() {
+        if v != want[i] {
+            t.Fatalf("partial = %v, want %v", out.ToSlice(), want)
+        }
+    }
+}
+
+func TestAsyncErrCollectAndPanic(t *testing.T) {
+    l := From([]int{1, 2, 3, 4})
+    kept, err := l.FilterAsync(context.Background(), 4, CollectErrors, func(ctx context.Context, v, i int) (bool, error) {
+        if v == 2 {
+            panic("bad record")
+        }
+        return v%2 == 0, nil
+    })
+    var pe *PanicError
+    if !errors.As(err, &pe) || pe.Value != "bad record" {
+        t.Fatalf("err = %v, want recovered panic", err)
+    }
+    if got := kept.ToSlice(); len(got) != 1 || got[0] != 4 {
+        t.Errorf("kept = %v, want [4]", got)
+    }
+
+    sum, err := ReduceAsync(context.Background(), l, 2, CollectErrors, 0,
+        func(ctx context.Context, v, i int) (int, error) { return v * v, nil },
+        func(acc, r, i int) int { return acc + r })
+    if err != nil || sum != 30 {
+        t.Errorf("ReduceAsync = %d, %v, want 30, nil", sum, err)
+    }
+}
+
+func TestMapStream(t *testing.T) {
+    xs := make([]int, 100)
+    for i := range xs {
+        xs[i] = i
+    }
+    l := From(xs)
+    // 让靠前的元素更慢，以产生乱序完成
+    slow := func(ctx context.Context, v, i int) int {
+        time.Sleep(time.Duration(100-v) * 10 * time.Microsecond)
+        return v * 2
+    }
+
+    i := 0
+    for v := range MapStream(context.Background(), l, 8, 16, slow) {
+        if v != i*2 {
+            t.Fatalf("result %d = %d, want %d", i, v, i*2)
+        }
+        i++
+    }
+    if i != 100 {
+        t.Fatalf("received %d results, want 100", i)
+    }
+
+    before := runtime.NumGoroutine()
+    n := 0
+    for range MapStreamSeq(context.Background(), l, 8, 16, slow) {
+        n++
+        if n == 10 {
+            break
+        }
+    }
+    deadline := time.Now().Add(time.Second)
+    for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+        time.Sleep(time.Millisecond)
+    }
+    if g := runtime.NumGoroutine(); g > before {
+        t.Errorf("goroutines after break = %d, want <= %d", g, before)
+    }
+}
+
+func TestOrderedHelpers(t *testing.T) {
+    l := From([]int{3, 1, 3, 2, 1})
+    if !Includes(l, 2) || IndexOf(l, 3) != 0 || LastIndexOf(l, 3) != 2 || Count(l, 1) != 2 {
+        t.Errorf("comparable helpers failed on %v", l)
+    }
+    if u := Unique(l).ToSlice(); len(u) != 3 || u[0] != 3 || u[2] != 2 {
+        t.Errorf("Unique = %v, want [3 1 2]", u)
+    }
+    if mn, _ := Min(l); mn != 1 {
+        t.Errorf("Min = %d", mn)
+    }
+    if mx, _ := Max(l); mx != 3 {
+        t.Errorf("Max = %d", mx)
+    }
+    if s := Sum(l); s != 10 {
+        t.Errorf("Sum = %d", s)
+    }
+
+    Sort(l)
+    Dedupe(l)
+    if got := l.ToSlice(); len(got) != 3 || got[0] != 1 || got[2] != 3 {
+        t.Errorf("Sort+Dedupe = %v, want [1 2 3]", got)
+    }
+    if i, ok := BinarySearch(l, 2); !ok || i != 1 {
+        t.Errorf("BinarySearch(2) = %d, %v", i, ok)
+    }
+
+    words := From([]string{"bb", "a", "cc", "d"})
+    words.SortStable(func(a, b string) bool { return len(a) < len(b) })
+    if got := words.Join(",", func(s string) string { return s }); got != "a,d,bb,cc" {
+        t.Errorf("SortStable = %s, want a,d,bb,cc", got)
+    }
+}
+
+func TestSetAndGrouping(t *testing.T) {
+    a := From([]int{1, 2, 2, 3, 4})
+    b := From([]int{4, 3, 5})
+    join := func(l *List[int]) string {
+        return l.Join(",", func(v int) string { return string(rune('0' + v)) })
+    }
+    if got := join(Union(a, b)); got != "1,2,3,4,5" {
+        t.Errorf("Union = %s", got)
+    }
+    if got := join(Intersect(a, b)); got != "3,4" {
+        t.Errorf("Intersect = %s", got)
+    }
+    if got := join(Difference(a, b)); got != "1,2,2" {
+        t.Errorf("Difference = %s", got)
+    }
+
+    parity := func(v, i int) int { return v % 2 }
+    keys, groups := GroupBy(a, parity)
+    if got := join(groups[0]); got != "2,2,4" {
+        t.Errorf("GroupBy[0] = %s", got)
+    }
+    // a 的第一个元素是奇数，因此 key 1 先出现
+    if len(keys) != 2 || keys[0] != 1 || keys[1] != 0 {
+        t.Errorf("GroupBy keys = %v, want [1 0]", keys)
+    }
+    if c := CountBy(a, parity); c[1] != 2 {
+        t.Errorf("CountBy[1] = %d, want 2", c[1])
+    }
+    if k := KeyBy(a, parity); k[1] != 3 {
+        t.Errorf("KeyBy[1] = %d, want 3", k[1])
+    }
+    even, odd := a.Partition(func(v, i int) bool { return v%2 == 0 })
+    if even.Len() != 3 || odd.Len() != 2 {
+        t.Errorf("Partition = %v, %v", even, odd)
+    }
+    if got := join(Flatten(From([]*List[int]{odd, nil, b}))); got != "1,3,4,3,5" {
+        t.Errorf("Flatten = %s", got)
+    }
+}
+
+func TestListEncoding(t *testing.T) {
+    type job struct {
+        ID   int
+        Name string
+    }
+    l := From([]job{{1, "a"}, {2, "b"}})
+
+    b, err := json.Marshal(l)
+    if err != nil || string(b) != `[{"ID":1,"Name":"a"},{"ID":2,"Name":"b"}]` {
+        t.Fatalf("Marshal = %s, %v", b, err)
+    }
+    var fromJSON List[job]
+    if err := json.Unmarshal(b, &fromJSON); err != nil || fromJSON.Get(1).Name != "b" {
+        t.Errorf("Unmarshal = %v, %v", fromJSON.ToSlice(), err)
+    }
+
+    var buf bytes.Buffer
+    if err := gob.NewEncoder(&buf).Encode(l); err != nil {
+        t.Fatal(err)
+    }
+    fromGob := New[job]()
+    if err := gob.NewDecoder(&buf).Decode(fromGob); err != nil || fromGob.Len() != 2 {
+        t.Errorf("gob Decode = %v, %v", fromGob.ToSlice(), err)
+    }
+
+    var empty List[int]
+    if b, _ := json.Marshal(&empty); string(b) != "[]" {
+        t.Errorf("Marshal empty = %s, want []", b)
+    }
+}
+
+func TestVectorPersistence(t *testing.T) {
+    const n = 5000
+    v0 := NewVector[int]()
+    ref := make([]int, 0, n)
+    versions := []*Vector[int]{v0}
+    for i := 0; i < n; i++ {
+        v0 = v0.Append(i)
+        ref = append(ref, i)
+        if i%1000 == 0 {
+            versions = append(versions, v0)
+        }
+    }
+    for i, x := range v0.All() {
+        if x != ref[i] {
+            t.Fatalf("All[%d] = %d, want %d", i, x, ref[i])
+        }
+    }
+
+    // With 不影响旧版本
+    v1 := v0.With(1234, -1).With(-1, -2)
+    if v0.Get(1234) != 1234 || v1.Get(1234) != -1 || v1.Get(n-1) != -2 || v0.Get(-1) != n-1 {
+        t.Errorf("With broke persistence")
+    }
+    for i, v := range versions {
+        if want := min(i*1000-999, n); i > 0 && v.Len() != want {
+            t.Errorf("version %d Len = %d, want %d", i, v.Len(), want)
+        }
+    }
+
+    // 在切片上追加会覆盖视图之后的位置，但原版本不变
+    s := v0.Slice(100, 200).Append(7, 8)
+    if s.Len() != 102 || s.Get(0) != 100 || s.Get(100) != 7 || v0.Get(200) != 200 {
+        t.Errorf("Slice/Append: len=%d first=%d appended=%d orig=%d", s.Len(), s.Get(0), s.Get(100), v0.Get(200))
+    }
+    p, last, _ := s.Pop()
+    if last != 8 || p.Len() != 101 || s.Len() != 102 {
+        t.Errorf("Pop = %d, len %d", last, p.Len())
+    }
+    if got := VectorFrom([]int{1, 2, 3}).Slice(-2).ToList().ToSlice(); len(got) != 2 || got[0] != 2 {
+        t.Errorf("Slice(-2) = %v", got)
+    }
+}
+
+func TestVectorReadAPI(t *testing.T) {
+    eq := func(a, b int) bool { return a == b }
+    xs := []int{1, 2, 3, 2, 1}
+    // 在切片视图上验证，确保偏移量处理正确
+    v := VectorFrom(append([]int{9, 9}, xs...)).Slice(2)
+    l := From(xs)
+
+    var back []int
+    for i, x := range v.Backward() {
+        if x != l.Get(i) {
+            t.Fatalf("Backward[%d] = %d, want %d", i, x, l.Get(i))
+        }
+        back = append(back, i)
+    }
+    if len(back) != 5 || back[0] != 4 || back[4] != 0 {
+        t.Errorf("Backward indices = %v", back)
+    }
+    if got, want := v.LastIndexOf(2, eq), l.LastIndexOf(2, eq); got != want || got != 3 {
+        t.Errorf("LastIndexOf = %d, want %d", got, want)
+    }
+    if v.LastIndexOf(7, eq) != -1 {
+        t.Errorf("LastIndexOf(missing) != -1")
+    }
+    if got := v.Count(1, eq); got != l.Count(1, eq) || got != 2 {
+        t.Errorf("Count = %d, want 2", got)
+    }
+    small := func(x, _ int) bool { return x < 3 }
+    if x, ok := v.FindLast(small); !ok || x != 1 {
+        t.Errorf("FindLast = %d, %v", x, ok)
+    }
+    if got := v.FindLastIndex(small); got != l.FindLastIndex(small) || got != 4 {
+        t.Errorf("FindLastIndex = %d, want 4", got)
+    }
+    if _, ok := v.FindLast(func(x, _ int) bool { return x > 5 }); ok {
+        t.Errorf("FindLast should not match 9s outside the view")
+    }
+}
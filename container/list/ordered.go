@@ -0,0 +1,121 @@
+package list
+
+import (
+	"cmp"
+	"slices"
+)
+
+// ---------- comparable / ordered 便捷函数（无需传入 eq / less） ----------
+
+// Number 是可以求和的数值类型约束。
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Includes 判断 List 是否包含 value，使用 == 比较。
+func Includes[T comparable](l *List[T], value T) bool { return slices.Contains(l.data, value) }
+
+// IndexOf 返回第一个等于 value 的索引，未找到返回 -1，使用 == 比较。
+func IndexOf[T comparable](l *List[T], value T) int { return slices.Index(l.data, value) }
+
+// LastIndexOf 返回最后一个等于 value 的索引，未找到返回 -1，使用 == 比较。
+func LastIndexOf[T comparable](l *List[T], value T) int {
+	for i := len(l.data) - 1; i >= 0; i-- {
+		if l.data[i] == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// Count 返回等于 value 的元素个数，使用 == 比较。
+func Count[T comparable](l *List[T], value T) int {
+	c := 0
+	for _, x := range l.data {
+		if x == value {
+			c++
+		}
+	}
+	return c
+}
+
+// RemoveFirst 移除第一个等于 value 的元素，使用 == 比较。
+func RemoveFirst[T comparable](l *List[T], value T) bool {
+	i := slices.Index(l.data, value)
+	if i < 0 {
+		return false
+	}
+	l.RemoveAt(i)
+	return true
+}
+
+// Unique 返回去重后的新 List，保留每个元素第一次出现的位置。
+func Unique[T comparable](l *List[T]) *List[T] {
+	seen := make(map[T]struct{}, len(l.data))
+	out := make([]T, 0, len(l.data))
+	for _, x := range l.data {
+		if _, ok := seen[x]; ok {
+			continue
+		}
+		seen[x] = struct{}{}
+		out = append(out, x)
+	}
+	return &List[T]{data: out}
+}
+
+// Dedupe 原地删除相邻的重复元素，对已排序的 List 即为去重。
+func Dedupe[T comparable](l *List[T]) {
+	n := len(l.data)
+	l.data = slices.Compact(l.data)
+	clear(l.data[len(l.data):n]) // 清零尾部，避免内存保持
+}
+
+// Sort 使用 cmp.Compare 顺序原地排序 List（不稳定）。
+func Sort[T cmp.Ordered](l *List[T]) { slices.Sort(l.data) }
+
+// SortStable 使用 less 原地稳定排序 List，时间复杂度 O(NlogN)。
+func (l *List[T]) SortStable(less func(a, b T) bool) {
+	slices.SortStableFunc(l.data, func(a, b T) int {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		}
+		return 0
+	})
+}
+
+// BinarySearch 在已升序排序的 List 中查找 value，返回其位置（或应插入的位置）以及是否找到。
+func BinarySearch[T cmp.Ordered](l *List[T], value T) (int, bool) {
+	return slices.BinarySearch(l.data, value)
+}
+
+// Min 返回最小元素，List 为空时返回 false。
+func Min[T cmp.Ordered](l *List[T]) (T, bool) {
+	if len(l.data) == 0 {
+		var zero T
+		return zero, false
+	}
+	return slices.Min(l.data), true
+}
+
+// Max 返回最大元素，List 为空时返回 false。
+func Max[T cmp.Ordered](l *List[T]) (T, bool) {
+	if len(l.data) == 0 {
+		var zero T
+		return zero, false
+	}
+	return slices.Max(l.data), true
+}
+
+// Sum 返回所有元素之和，空 List 返回 0。
+func Sum[T Number](l *List[T]) T {
+	var s T
+	for _, x := range l.data {
+		s += x
+	}
+	return s
+}
@@ -0,0 +1,138 @@
+package list
+
+// ---------- 集合运算与分组（结果保持输入顺序） ----------
+
+// Union 返回 a 与 b 的并集（去重），先按 a 的顺序，再追加 b 中新出现的元素。
+func Union[T comparable](a, b *List[T]) *List[T] {
+	return UnionBy(a, b, func(v T) T { return v })
+}
+
+// UnionBy 按 key 计算并集，key 相同的元素只保留第一次出现的那个。
+func UnionBy[T any, K comparable](a, b *List[T], key func(v T) K) *List[T] {
+	seen := make(map[K]struct{}, len(a.data)+len(b.data))
+	out := make([]T, 0, len(a.data)+len(b.data))
+	for _, xs := range [][]T{a.data, b.data} {
+		for _, x := range xs {
+			k := key(x)
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+			out = append(out, x)
+		}
+	}
+	return &List[T]{data: out}
+}
+
+// Intersect 返回同时出现在 a 和 b 中的元素（去重），按 a 的顺序。
+func Intersect[T comparable](a, b *List[T]) *List[T] {
+	return IntersectBy(a, b, func(v T) T { return v })
+}
+
+// IntersectBy 按 key 计算交集，返回 a 中 key 也出现在 b 中的元素（去重），按 a 的顺序。
+func IntersectBy[T any, K comparable](a, b *List[T], key func(v T) K) *List[T] {
+	inB := keySet(b, key)
+	seen := make(map[K]struct{})
+	out := make([]T, 0)
+	for _, x := range a.data {
+		k := key(x)
+		if _, ok := inB[k]; !ok {
+			continue
+		}
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		out = append(out, x)
+	}
+	return &List[T]{data: out}
+}
+
+// Difference 返回在 a 中但不在 b 中的元素，按 a 的顺序，保留 a 中的重复元素。
+func Difference[T comparable](a, b *List[T]) *List[T] {
+	return DifferenceBy(a, b, func(v T) T { return v })
+}
+
+// DifferenceBy 按 key 计算差集，返回 a 中 key 不在 b 中出现的元素，按 a 的顺序。
+func DifferenceBy[T any, K comparable](a, b *List[T], key func(v T) K) *List[T] {
+	inB := keySet(b, key)
+	out := make([]T, 0, len(a.data))
+	for _, x := range a.data {
+		if _, ok := inB[key(x)]; !ok {
+			out = append(out, x)
+		}
+	}
+	return &List[T]{data: out}
+}
+
+func keySet[T any, K comparable](l *List[T], key func(v T) K) map[K]struct{} {
+	set := make(map[K]struct{}, len(l.data))
+	for _, x := range l.data {
+		set[key(x)] = struct{}{}
+	}
+	return set
+}
+
+// GroupBy 按 keyFn 分组（JS: Object.groupBy），keys 按每个 key 第一次出现的顺序排列，每组内元素保持原顺序。
+func GroupBy[T any, K comparable](l *List[T], keyFn func(v T, i int) K) (keys []K, groups map[K]*List[T]) {
+	groups = make(map[K]*List[T])
+	for i, x := range l.data {
+		k := keyFn(x, i)
+		g, ok := groups[k]
+		if !ok {
+			g = New[T]()
+			groups[k] = g
+			keys = append(keys, k)
+		}
+		g.data = append(g.data, x)
+	}
+	return keys, groups
+}
+
+// Partition 按条件拆分为满足与不满足的两个 List，各自保持原顺序。
+func (l *List[T]) Partition(pred func(v T, i int) bool) (yes, no *List[T]) {
+	yes, no = New[T](), New[T]()
+	for i, x := range l.data {
+		if pred(x, i) {
+			yes.data = append(yes.data, x)
+		} else {
+			no.data = append(no.data, x)
+		}
+	}
+	return yes, no
+}
+
+// CountBy 统计每个 key 的元素个数。
+func CountBy[T any, K comparable](l *List[T], keyFn func(v T, i int) K) map[K]int {
+	counts := make(map[K]int)
+	for i, x := range l.data {
+		counts[keyFn(x, i)]++
+	}
+	return counts
+}
+
+// KeyBy 以 keyFn 的结果为键建立索引，key 重复时后出现的元素覆盖先出现的。
+func KeyBy[T any, K comparable](l *List[T], keyFn func(v T, i int) K) map[K]T {
+	m := make(map[K]T, len(l.data))
+	for i, x := range l.data {
+		m[keyFn(x, i)] = x
+	}
+	return m
+}
+
+// Flatten 将嵌套的 List 展开一层（JS: flat()），nil 子列表会被跳过。
+func Flatten[T any](l *List[*List[T]]) *List[T] {
+	n := 0
+	for _, sub := range l.data {
+		if sub != nil {
+			n += len(sub.data)
+		}
+	}
+	out := make([]T, 0, n)
+	for _, sub := range l.data {
+		if sub != nil {
+			out = append(out, sub.data...)
+		}
+	}
+	return &List[T]{data: out}
+}
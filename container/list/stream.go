@@ -0,0 +1,62 @@
+package list
+
+import (
+	"context"
+	"iter"
+	"runtime"
+
+	"github.com/leoxiang66/go-patterns/internal/reorder"
+)
+
+// MapStream 用固定数量的 worker 并发映射 List，按输入顺序把结果发送到返回的通道，结果一就绪即可被下游消费。
+// 已派发但尚未发出的元素最多 buffer 个（即重排缓冲区上限），buffer 小于 workers 时按 workers 计。
+// 所有结果发出或 ctx 结束后通道关闭；调用方提前停止读取时必须取消 ctx，否则内部 goroutine 会阻塞。
+func MapStream[T any, R any](
+	ctx context.Context,
+	l *List[T],
+	workers int,
+	buffer int,
+	fn func(ctx context.Context, v T, i int) R,
+) <-chan R {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if buffer < workers {
+		buffer = workers
+	}
+
+	data := l.ToSlice() // 拷贝，避免 goroutine 里读到被外部改动的值
+	idx := make(chan int)
+	go func() {
+		defer close(idx)
+		for i := range data {
+			select {
+			case <-ctx.Done():
+				return
+			case idx <- i:
+			}
+		}
+	}()
+	out := make(chan R)
+	reorder.Map(ctx, workers, buffer, func(i int) R { return fn(ctx, data[i], i) }, idx, out)
+	return out
+}
+
+// MapStreamSeq 与 MapStream 相同，但返回按输入顺序产出结果的迭代器；提前结束遍历会自动停止所有 worker。
+func MapStreamSeq[T any, R any](
+	ctx context.Context,
+	l *List[T],
+	workers int,
+	buffer int,
+	fn func(ctx context.Context, v T, i int) R,
+) iter.Seq[R] {
+	return func(yield func(R) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		for r := range MapStream(ctx, l, workers, buffer, fn) {
+			if !yield(r) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package list
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// SyncList 是并发安全的 List，接口与 List 保持一致。
+// 写操作持有写锁；带回调的读操作（ForEach、Find、迭代器等）在写时复制的快照上进行，
+// 不持有锁，因此回调中可以安全地再次调用 SyncList 的方法。
+type SyncList[T any] struct {
+	mu   sync.RWMutex
+	l    *List[T]
+	snap atomic.Pointer[List[T]] // 只读快照，写操作后失效
+}
+
+// NewSync 创建一个空的 SyncList。
+func NewSync[T any]() *SyncList[T] { return &SyncList[T]{l: New[T]()} }
+
+// SyncFrom 根据给定切片创建一个新的 SyncList。
+func SyncFrom[T any](xs []T) *SyncList[T] { return &SyncList[T]{l: From(xs)} }
+
+// Snapshot 返回当前内容的私有副本，调用方可以任意修改，不影响 SyncList 及其他调用方。
+func (s *SyncList[T]) Snapshot() *List[T] { return s.view().Clone() }
+
+// view 返回当前内容的只读快照，两次写操作之间的调用共享同一份副本，只供内部的只读方法使用。
+func (s *SyncList[T]) view() *List[T] {
+	if p := s.snap.Load(); p != nil {
+		return p
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	// 持有读锁期间没有写者，复制出的快照一定是最新的
+	cp := s.l.Clone()
+	if !s.snap.CompareAndSwap(nil, cp) {
+		return s.snap.Load()
+	}
+	return cp
+}
+
+// write 在写锁下修改 List 并使快照失效。
+func (s *SyncList[T]) write(fn func(l *List[T])) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.l)
+	s.snap.Store(nil)
+}
+
+// read 在读锁下读取 List。
+func (s *SyncList[T]) read(fn func(l *List[T])) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(s.l)
+}
+
+// Do 在写锁下执行任意复合操作，fn 中不得调用 SyncList 的方法。
+func (s *SyncList[T]) Do(fn func(l *List[T])) { s.write(fn) }
+
+// ---------- 基础 ----------
+
+// Len 返回 SyncList 的长度。
+func (s *SyncList[T]) Len() (n int) { s.read(func(l *List[T]) { n = l.Len() }); return }
+
+// Cap 返回 SyncList 的容量。
+func (s *SyncList[T]) Cap() (n int) { s.read(func(l *List[T]) { n = l.Cap() }); return }
+
+// ToSlice 返回 SyncList 的副本切片。
+func (s *SyncList[T]) ToSlice() []T { return s.view().ToSlice() }
+
+// String 返回 SyncList 的字符串表示。
+func (s *SyncList[T]) String() string { return s.view().String() }
+
+// Clone 返回 SyncList 的副本。
+func (s *SyncList[T]) Clone() *SyncList[T] { return SyncFrom(s.view().data) }
+
+// ---------- 读写/访问 ----------
+
+// Get 获取指定索引的元素，支持负索引，越界会 panic。
+func (s *SyncList[T]) Get(i int) (v T) { s.read(func(l *List[T]) { v = l.Get(i) }); return }
+
+// Set 设置指定索引的元素，支持负索引，越界会 panic。
+func (s *SyncList[T]) Set(i int, v T) { s.write(func(l *List[T]) { l.Set(i, v) }) }
+
+// At 获取指定索引的元素，支持负索引，越界返回 false。
+func (s *SyncList[T]) At(i int) (v T, ok bool) {
+	s.read(func(l *List[T]) { v, ok = l.At(i) })
+	return
+}
+
+// With 返回修改某索引后的新 List 副本，原 SyncList 不变。
+func (s *SyncList[T]) With(i int, v T) *List[T] { return s.view().With(i, v) }
+
+// ---------- 增删改 ----------
+
+// Append 在 SyncList 末尾添加元素。
+func (s *SyncList[T]) Append(items ...T) { s.write(func(l *List[T]) { l.Append(items...) }) }
+
+// Push 是 Append 的别名。
+func (s *SyncList[T]) Push(items ...T) { s.Append(items...) }
+
+// Extend 扩展 SyncList，添加切片中的所有元素。
+func (s *SyncList[T]) Extend(xs []T) { s.write(func(l *List[T]) { l.Extend(xs) }) }
+
+// Unshift 在 SyncList 前端插入元素。
+func (s *SyncList[T]) Unshift(items ...T) { s.write(func(l *List[T]) { l.Unshift(items...) }) }
+
+// Shift 移除并返回第一个元素。
+func (s *SyncList[T]) Shift() (v T, ok bool) {
+	s.write(func(l *List[T]) { v, ok = l.Shift() })
+	return
+}
+
+// Pop 移除并返回最后一个元素。
+func (s *SyncList[T]) Pop() (v T, ok bool) {
+	s.write(func(l *List[T]) { v, ok = l.Pop() })
+	return
+}
+
+// Insert 在指定位置插入元素，支持负索引。
+func (s *SyncList[T]) Insert(i int, v T) { s.write(func(l *List[T]) { l.Insert(i, v) }) }
+
+// RemoveFirst 移除第一个等于 value 的元素，eq 为比较器。
+func (s *SyncList[T]) RemoveFirst(value T, eq func(a, b T) bool) (ok bool) {
+	s.write(func(l *List[T]) { ok = l.RemoveFirst(value, eq) })
+	return
+}
+
+// RemoveAt 移除指定索引的元素，返回被移除的值和是否成功。
+func (s *SyncList[T]) RemoveAt(i int) (v T, ok bool) {
+	s.write(func(l *List[T]) { v, ok = l.RemoveAt(i) })
+	return
+}
+
+// Clear 清空 SyncList。
+func (s *SyncList[T]) Clear() { s.write(func(l *List[T]) { l.Clear() }) }
+
+// Splice 删除并插入元素，返回被删除的元素列表，并在当前 SyncList 上做就地修改。
+func (s *SyncList[T]) Splice(start, deleteCount int, items ...T) (removed *List[T]) {
+	s.write(func(l *List[T]) { removed = l.Splice(start, deleteCount, items...) })
+	return
+}
+
+// CopyWithin 将指定区间的元素复制到目标位置。
+func (s *SyncList[T]) CopyWithin(target int, start int, endOpt ...int) {
+	s.write(func(l *List[T]) { l.CopyWithin(target, start, endOpt...) })
+}
+
+// Fill 用指定值填充区间元素。
+func (s *SyncList[T]) Fill(value T, startEnd ...int) {
+	s.write(func(l *List[T]) { l.Fill(value, startEnd...) })
+}
+
+// ---------- 原子复合操作 ----------
+
+// AppendIfAbsent 当不存在等于 value 的元素时追加，返回是否追加。
+func (s *SyncList[T]) AppendIfAbsent(value T, eq func(a, b T) bool) (added bool) {
+	s.write(func(l *List[T]) {
+		if !l.Includes(value, eq) {
+			l.Append(value)
+			added = true
+		}
+	})
+	return
+}
+
+// Update 原子地用 fn 的结果替换指定索引的元素，支持负索引，越界返回 false。
+func (s *SyncList[T]) Update(i int, fn func(v T) T) (ok bool) {
+	s.write(func(l *List[T]) {
+		ii := normIndex(l.Len(), i)
+		if ii < 0 {
+			return
+		}
+		l.data[ii] = fn(l.data[ii])
+		ok = true
+	})
+	return
+}
+
+// ---------- 查询/搜索 ----------
+
+// Includes 判断 SyncList 是否包含指定元素，eq 为比较器。
+func (s *SyncList[T]) Includes(value T, eq func(a, b T) bool) bool {
+	return s.view().Includes(value, eq)
+}
+
+// IndexOf 返回第一个等于 value 的索引，未找到返回 -1。
+func (s *SyncList[T]) IndexOf(value T, eq func(a, b T) bool) int {
+	return s.view().IndexOf(value, eq)
+}
+
+// LastIndexOf 返回最后一个等于 value 的索引，未找到返回 -1。
+func (s *SyncList[T]) LastIndexOf(value T, eq func(a, b T) bool) int {
+	return s.view().LastIndexOf(value, eq)
+}
+
+// Count 返回等于 value 的元素个数。
+func (s *SyncList[T]) Count(value T, eq func(a, b T) bool) int {
+	return s.view().Count(value, eq)
+}
+
+// Find 返回第一个满足条件的元素。
+func (s *SyncList[T]) Find(pred func(v T, i int) bool) (T, bool) { return s.view().Find(pred) }
+
+// FindIndex 返回第一个满足条件的元素索引。
+func (s *SyncList[T]) FindIndex(pred func(v T, i int) bool) int {
+	return s.view().FindIndex(pred)
+}
+
+// FindLast 返回最后一个满足条件的元素。
+func (s *SyncList[T]) FindLast(pred func(v T, i int) bool) (T, bool) {
+	return s.view().FindLast(pred)
+}
+
+// FindLastIndex 返回最后一个满足条件的元素索引。
+func (s *SyncList[T]) FindLastIndex(pred func(v T, i int) bool) int {
+	return s.view().FindLastIndex(pred)
+}
+
+// ---------- 遍历/变换 ----------
+
+// ForEach 在快照上只读遍历。
+func (s *SyncList[T]) ForEach(fn func(v T, i int)) { s.view().ForEach(fn) }
+
+// ForEachAsync 在快照上并发只读遍历，支持最大 goroutine 数。
+func (s *SyncList[T]) ForEachAsync(ctx context.Context, maxGoroutines int, fn func(v T, i int)) error {
+	return s.view().ForEachAsync(ctx, maxGoroutines, fn)
+}
+
+// Map 返回一个新的 List，元素为 fn 映射结果（类型为 any）。
+func (s *SyncList[T]) Map(fn func(v T, i int) any) *List[any] { return s.view().Map(fn) }
+
+// MapInPlace 原地映射元素，fn 在写锁下执行。
+func (s *SyncList[T]) MapInPlace(fn func(v T, i int) T) {
+	s.write(func(l *List[T]) { l.MapInPlace(fn) })
+}
+
+// Filter 返回所有满足条件的元素组成的新 List。
+func (s *SyncList[T]) Filter(pred func(v T, i int) bool) *List[T] {
+	return s.view().Filter(pred)
+}
+
+// Some 判断是否存在满足条件的元素。
+func (s *SyncList[T]) Some(pred func(v T, i int) bool) bool { return s.view().Some(pred) }
+
+// Every 判断所有元素是否都满足条件。
+func (s *SyncList[T]) Every(pred func(v T, i int) bool) bool { return s.view().Every(pred) }
+
+// All 返回快照上 (下标, 元素) 的迭代器。
+func (s *SyncList[T]) All() iter.Seq2[int, T] { return s.view().All() }
+
+// Values 返回快照上元素的迭代器。
+func (s *SyncList[T]) Values() iter.Seq[T] { return s.view().Values() }
+
+// Backward 返回快照上从后往前的迭代器。
+func (s *SyncList[T]) Backward() iter.Seq2[int, T] { return s.view().Backward() }
+
+// ---------- 切片/排序/反转 ----------
+
+// Slice 返回指定区间的新 List，不修改原 SyncList。
+func (s *SyncList[T]) Slice(startEnd ...int) *List[T] { return s.view().Slice(startEnd...) }
+
+// Reverse 原地反转 SyncList。
+func (s *SyncList[T]) Reverse() { s.write(func(l *List[T]) { l.Reverse() }) }
+
+// ToReversed 返回反转后的新 List。
+func (s *SyncList[T]) ToReversed() *List[T] { return s.view().ToReversed() }
+
+// Sort 原地排序 SyncList，less 在写锁下执行。
+func (s *SyncList[T]) Sort(less func(a, b T) bool) { s.write(func(l *List[T]) { l.Sort(less) }) }
+
+// ToSorted 返回排序后的新 List。
+func (s *SyncList[T]) ToSorted(less func(a, b T) bool) *List[T] {
+	return s.view().ToSorted(less)
+}
+
+// ToSpliced 返回执行 splice 后的新 List，原 SyncList 不变。
+func (s *SyncList[T]) ToSpliced(start, deleteCount int, items ...T) *List[T] {
+	return s.view().ToSpliced(start, deleteCount, items...)
+}
+
+// Join 用分隔符连接元素，toStr 为元素转字符串函数。
+func (s *SyncList[T]) Join(sep string, toStr func(v T) string) string {
+	return s.view().Join(sep, toStr)
+}
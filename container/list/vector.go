@@ -0,0 +1,369 @@
+package list
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// Vector 是持久化（不可变、结构共享）的列表，基于 32 路前缀树加尾部缓冲实现（同 Clojure 的 PersistentVector）。
+// 所有"修改"操作都返回新的 Vector，旧版本保持有效并与新版本共享未修改的节点，适合做撤销历史和配置版本。
+// Get/With/Append 为 O(log32 N)，Slice/Pop 为 O(1)（与原 Vector 共享底层数据）。
+type Vector[T any] struct {
+	t   *trie[T]
+	off int // 视图在 t 中的起始位置
+	n   int // 视图长度
+}
+
+const (
+	vecBits  = 5
+	vecWidth = 1 << vecBits
+	vecMask  = vecWidth - 1
+)
+
+type vnode[T any] struct {
+	kids []*vnode[T] // 内部节点
+	vals []T         // 叶子节点
+}
+
+// trie 是不可变的 32 路前缀树，末尾不满 32 个的元素放在 tail 中
+type trie[T any] struct {
+	root  *vnode[T]
+	shift uint
+	cnt   int
+	tail  []T
+}
+
+// NewVector 创建一个空的 Vector。
+func NewVector[T any]() *Vector[T] {
+	return &Vector[T]{t: &trie[T]{root: &vnode[T]{}, shift: vecBits}}
+}
+
+// VectorFrom 根据给定切片创建一个新的 Vector。
+func VectorFrom[T any](xs []T) *Vector[T] { return NewVector[T]().Append(xs...) }
+
+// ToVector 返回内容与 List 相同的 Vector。
+func (l *List[T]) ToVector() *Vector[T] { return VectorFrom(l.data) }
+
+// ---------- 前缀树 ----------
+
+func (t *trie[T]) tailOff() int {
+	if t.cnt < vecWidth {
+		return 0
+	}
+	return ((t.cnt - 1) >> vecBits) << vecBits
+}
+
+func (t *trie[T]) get(i int) T {
+	if i >= t.tailOff() {
+		return t.tail[i&vecMask]
+	}
+	node := t.root
+	for level := t.shift; level > 0; level -= vecBits {
+		node = node.kids[(i>>level)&vecMask]
+	}
+	return node.vals[i&vecMask]
+}
+
+// set 路径复制：只复制从根到目标叶子的节点
+func (t *trie[T]) set(i int, v T) *trie[T] {
+	if i >= t.tailOff() {
+		tail := make([]T, len(t.tail))
+		copy(tail, t.tail)
+		tail[i&vecMask] = v
+		return &trie[T]{root: t.root, shift: t.shift, cnt: t.cnt, tail: tail}
+	}
+	return &trie[T]{root: setNode(t.root, t.shift, i, v), shift: t.shift, cnt: t.cnt, tail: t.tail}
+}
+
+func setNode[T any](node *vnode[T], level uint, i int, v T) *vnode[T] {
+	if level == 0 {
+		vals := make([]T, len(node.vals))
+		copy(vals, node.vals)
+		vals[i&vecMask] = v
+		return &vnode[T]{vals: vals}
+	}
+	kids := make([]*vnode[T], len(node.kids))
+	copy(kids, node.kids)
+	sub := (i >> level) & vecMask
+	kids[sub] = setNode(kids[sub], level-vecBits, i, v)
+	return &vnode[T]{kids: kids}
+}
+
+func (t *trie[T]) push(v T) *trie[T] {
+	// 尾部未满：复制尾部后追加
+	if t.cnt-t.tailOff() < vecWidth {
+		tail := make([]T, len(t.tail), len(t.tail)+1)
+		copy(tail, t.tail)
+		return &trie[T]{root: t.root, shift: t.shift, cnt: t.cnt + 1, tail: append(tail, v)}
+	}
+	// 尾部已满：把尾部作为叶子挂入树中
+	leaf := &vnode[T]{vals: t.tail}
+	root, shift := t.root, t.shift
+	if (t.cnt >> vecBits) > (1 << t.shift) {
+		// 根已满，树长高一层
+		root = &vnode[T]{kids: []*vnode[T]{t.root, newPath(t.shift, leaf)}}
+		shift += vecBits
+	} else {
+		root = t.pushTail(t.shift, t.root, leaf)
+	}
+	return &trie[T]{root: root, shift: shift, cnt: t.cnt + 1, tail: []T{v}}
+}
+
+func (t *trie[T]) pushTail(level uint, parent *vnode[T], leaf *vnode[T]) *vnode[T] {
+	sub := ((t.cnt - 1) >> level) & vecMask
+	kids := make([]*vnode[T], len(parent.kids), max(len(parent.kids), sub+1))
+	copy(kids, parent.kids)
+	var child *vnode[T]
+	if level == vecBits {
+		child = leaf
+	} else if sub < len(parent.kids) {
+		child = t.pushTail(level-vecBits, parent.kids[sub], leaf)
+	} else {
+		child = newPath(level-vecBits, leaf)
+	}
+	if sub < len(kids) {
+		kids[sub] = child
+	} else {
+		kids = append(kids, child)
+	}
+	return &vnode[T]{kids: kids}
+}
+
+func newPath[T any](level uint, leaf *vnode[T]) *vnode[T] {
+	if level == 0 {
+		return leaf
+	}
+	return &vnode[T]{kids: []*vnode[T]{newPath(level-vecBits, leaf)}}
+}
+
+// ---------- 读取 ----------
+
+// Len 返回 Vector 的长度。
+func (v *Vector[T]) Len() int { return v.n }
+
+// Get 获取指定索引的元素，支持负索引，越界会 panic。
+func (v *Vector[T]) Get(i int) T {
+	ii := normIndex(v.n, i)
+	if ii < 0 {
+		panic("index out of range")
+	}
+	return v.t.get(v.off + ii)
+}
+
+// At 获取指定索引的元素，支持负索引，越界返回 false。
+func (v *Vector[T]) At(i int) (T, bool) {
+	ii := normIndex(v.n, i)
+	if ii < 0 {
+		var zero T
+		return zero, false
+	}
+	return v.t.get(v.off + ii), true
+}
+
+// ToSlice 返回 Vector 的副本切片。
+func (v *Vector[T]) ToSlice() []T {
+	out := make([]T, 0, v.n)
+	for x := range v.Values() {
+		out = append(out, x)
+	}
+	return out
+}
+
+// ToList 返回内容相同的可变 List。
+func (v *Vector[T]) ToList() *List[T] { return &List[T]{data: v.ToSlice()} }
+
+// String 返回 Vector 的字符串表示。
+func (v *Vector[T]) String() string { return fmt.Sprintf("%v", v.ToSlice()) }
+
+// All 返回按下标顺序遍历 (下标, 元素) 的迭代器，按叶子批量读取。
+func (v *Vector[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		for i < v.n {
+			// 取出包含第 i 个元素的整块叶子，减少重复的树遍历
+			idx := v.off + i
+			var block []T
+			if idx >= v.t.tailOff() {
+				block = v.t.tail
+			} else {
+				node := v.t.root
+				for level := v.t.shift; level > 0; level -= vecBits {
+					node = node.kids[(idx>>level)&vecMask]
+				}
+				block = node.vals
+			}
+			for j := idx & vecMask; j < len(block) && i < v.n; j++ {
+				if !yield(i, block[j]) {
+					return
+				}
+				i++
+			}
+		}
+	}
+}
+
+// Values 返回按下标顺序遍历元素的迭代器。
+func (v *Vector[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, x := range v.All() {
+			if !yield(x) {
+				return
+			}
+		}
+	}
+}
+
+// Backward 返回从后往前遍历 (下标, 元素) 的迭代器。
+func (v *Vector[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := v.n - 1; i >= 0; i-- {
+			if !yield(i, v.t.get(v.off+i)) {
+				return
+			}
+		}
+	}
+}
+
+// ForEach 只读遍历 Vector。
+func (v *Vector[T]) ForEach(fn func(x T, i int)) {
+	for i, x := range v.All() {
+		fn(x, i)
+	}
+}
+
+// Includes 判断 Vector 是否包含指定元素，eq 为比较器。
+func (v *Vector[T]) Includes(value T, eq func(a, b T) bool) bool {
+	return v.IndexOf(value, eq) >= 0
+}
+
+// IndexOf 返回第一个等于 value 的索引，未找到返回 -1。
+func (v *Vector[T]) IndexOf(value T, eq func(a, b T) bool) int {
+	return v.FindIndex(func(x T, _ int) bool { return eq(x, value) })
+}
+
+// LastIndexOf 返回最后一个等于 value 的索引，未找到返回 -1。
+func (v *Vector[T]) LastIndexOf(value T, eq func(a, b T) bool) int {
+	return v.FindLastIndex(func(x T, _ int) bool { return eq(x, value) })
+}
+
+// Count 返回等于 value 的元素个数。
+func (v *Vector[T]) Count(value T, eq func(a, b T) bool) int {
+	c := 0
+	for x := range v.Values() {
+		if eq(x, value) {
+			c++
+		}
+	}
+	return c
+}
+
+// Find 返回第一个满足条件的元素。
+func (v *Vector[T]) Find(pred func(x T, i int) bool) (T, bool) {
+	for i, x := range v.All() {
+		if pred(x, i) {
+			return x, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// FindIndex 返回第一个满足条件的元素索引。
+func (v *Vector[T]) FindIndex(pred func(x T, i int) bool) int {
+	for i, x := range v.All() {
+		if pred(x, i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// FindLast 返回最后一个满足条件的元素。
+func (v *Vector[T]) FindLast(pred func(x T, i int) bool) (T, bool) {
+	for i, x := range v.Backward() {
+		if pred(x, i) {
+			return x, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// FindLastIndex 返回最后一个满足条件的元素索引。
+func (v *Vector[T]) FindLastIndex(pred func(x T, i int) bool) int {
+	for i, x := range v.Backward() {
+		if pred(x, i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Some 判断是否存在满足条件的元素。
+func (v *Vector[T]) Some(pred func(x T, i int) bool) bool { return v.FindIndex(pred) >= 0 }
+
+// Every 判断所有元素是否都满足条件。
+func (v *Vector[T]) Every(pred func(x T, i int) bool) bool {
+	return v.FindIndex(func(x T, i int) bool { return !pred(x, i) }) < 0
+}
+
+// Join 用分隔符连接 Vector 元素，toStr 为元素转字符串函数。
+func (v *Vector[T]) Join(sep string, toStr func(x T) string) string {
+	sb := strings.Builder{}
+	for i, x := range v.All() {
+		if i > 0 {
+			sb.WriteString(sep)
+		}
+		sb.WriteString(toStr(x))
+	}
+	return sb.String()
+}
+
+// ---------- 持久化"修改"（返回新版本，原 Vector 不变） ----------
+
+// With 返回修改某索引后的新 Vector，支持负索引，越界会 panic。
+func (v *Vector[T]) With(i int, x T) *Vector[T] {
+	ii := normIndex(v.n, i)
+	if ii < 0 {
+		panic("index out of range")
+	}
+	return &Vector[T]{t: v.t.set(v.off+ii, x), off: v.off, n: v.n}
+}
+
+// Append 返回在末尾添加元素后的新 Vector。
+func (v *Vector[T]) Append(items ...T) *Vector[T] {
+	t, n := v.t, v.n
+	for _, x := range items {
+		if end := v.off + n; end < t.cnt {
+			// 视图之后还有旧数据（来自 Slice/Pop），覆盖而不是追加
+			t = t.set(end, x)
+		} else {
+			t = t.push(x)
+		}
+		n++
+	}
+	return &Vector[T]{t: t, off: v.off, n: n}
+}
+
+// Pop 返回去掉最后一个元素后的新 Vector 以及被去掉的元素。
+func (v *Vector[T]) Pop() (*Vector[T], T, bool) {
+	if v.n == 0 {
+		var zero T
+		return v, zero, false
+	}
+	return &Vector[T]{t: v.t, off: v.off, n: v.n - 1}, v.t.get(v.off + v.n - 1), true
+}
+
+// Slice 返回指定区间的新 Vector（end 为开区间，支持负索引），与原 Vector 共享底层数据。
+func (v *Vector[T]) Slice(startEnd ...int) *Vector[T] {
+	var start, end *int
+	if len(startEnd) >= 1 {
+		start = &startEnd[0]
+	}
+	if len(startEnd) >= 2 {
+		end = &startEnd[1]
+	}
+	s, e := normRange(v.n, start, end)
+	return &Vector[T]{t: v.t, off: v.off + s, n: e - s}
+}
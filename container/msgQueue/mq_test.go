@@ -0,0 +1,215 @@
+package msgqueue
+
+import (
+	"context"
+	"encoding/binary"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func encode(i uint64) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, i)
+	return b
+}
+
+func TestRingMQStress(t *testing.T) {
+	const (
+		producers = 4
+		consumers = 4
+		perProd   = 5000
+	)
+	q := NewRingMQ(64, "stress")
+
+	var prodWg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		prodWg.Add(1)
+		go func(p int) {
+			defer prodWg.Done()
+			for i := 0; i < perProd; {
+				if q.Enq(encode(uint64(p*perProd+i))) == nil {
+					i++
+				} else {
+					runtime.Gosched() // 队列已满，让出 CPU 给消费者
+				}
+			}
+		}(p)
+	}
+
+	seen := make([]int, producers*perProd)
+	var mu sync.Mutex
+	var consWg sync.WaitGroup
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for c := 0; c < consumers; c++ {
+		consWg.Add(1)
+		go func() {
+			defer consWg.Done()
+			for {
+				msg, err := q.Deq(ctx)
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				seen[binary.LittleEndian.Uint64(msg)]++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	prodWg.Wait()
+	q.Destroy() // 消费者取完剩余消息后退出
+	consWg.Wait()
+
+	for i, n := range seen {
+		if n != 1 {
+			t.Fatalf("message %d received %d times", i, n)
+		}
+	}
+}
+
+func TestRingMQLifecycle(t *testing.T) {
+	q := NewRingMQ(2, "dev")
+	q.Enq([]byte("a"))
+	q.Enq([]byte("b"))
+	if err := q.Enq([]byte("c")); err == nil {
+		t.Error("Enq on full MQ should fail")
+	}
+	if q.Len() != 2 {
+		t.Errorf("Len = %d, want 2", q.Len())
+	}
+	q.Clear()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := q.Deq(ctx); err == nil {
+		t.Error("Deq on empty MQ should time out")
+	}
+
+	q.Destroy()
+	if q.IsLive() || q.Enq([]byte("x")) == nil {
+		t.Error("destroyed MQ should reject Enq")
+	}
+	q.Renew()
+	if !q.IsLive() || q.Enq([]byte("x")) != nil {
+		t.Error("renewed MQ should accept Enq")
+	}
+}
+
+// TestRingMQEnqDestroyRace 与 Destroy 并发的 Enq 要么失败，要么消息能被取出，不会“成功但丢失”
+func TestRingMQEnqDestroyRace(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for round := 0; round < 50; round++ {
+		q := NewRingMQ(1<<12, "race")
+		var sent, received int64
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		for p := 0; p < 4; p++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 500; i++ {
+					if q.Enq(encode(uint64(i))) == nil {
+						mu.Lock()
+						sent++
+						mu.Unlock()
+					}
+				}
+			}()
+		}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				if _, err := q.Deq(ctx); err != nil {
+					return
+				}
+				received++
+			}
+		}()
+		runtime.Gosched()
+		q.Destroy()
+		<-done
+		wg.Wait()
+		if sent != received {
+			t.Fatalf("round %d: %d messages accepted, %d received", round, sent, received)
+		}
+	}
+}
+
+// benchmarkMQ 并行入队，由单独的 goroutine 持续出队
+func benchmarkMQ(b *testing.B, enq func([]byte) bool, deq func() bool) {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				if !deq() {
+					runtime.Gosched()
+				}
+			}
+		}
+	}()
+	msg := []byte("payload")
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			for !enq(msg) {
+				runtime.Gosched()
+			}
+		}
+	})
+	b.StopTimer()
+	close(stop)
+	<-done
+}
+
+func BenchmarkRingMQ(b *testing.B) {
+	q := NewRingMQ(1024, "bench")
+	ctx := context.Background()
+	benchmarkMQ(b, func(m []byte) bool { return q.Enq(m) == nil }, func() bool {
+		if q.Len() == 0 {
+			return false
+		}
+		q.Deq(ctx)
+		return true
+	})
+}
+
+func BenchmarkChanMQ(b *testing.B) {
+	q := NewChanMQ(1024, "bench")
+	ctx := context.Background()
+	benchmarkMQ(b, func(m []byte) bool { return q.Enq(m) == nil }, func() bool {
+		if q.Len() == 0 {
+			return false
+		}
+		q.Deq(ctx)
+		return true
+	})
+}
+
+func BenchmarkBufferedChan(b *testing.B) {
+	ch := make(chan []byte, 1024)
+	benchmarkMQ(b, func(m []byte) bool {
+		select {
+		case ch <- m:
+			return true
+		default:
+			return false
+		}
+	}, func() bool {
+		select {
+		case <-ch:
+			return true
+		default:
+			return false
+		}
+	})
+}
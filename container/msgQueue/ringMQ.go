@@ -0,0 +1,240 @@
+package msgqueue
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ringCell 环形队列的槽位，seq 用于协调生产者与消费者（Vyukov 有界 MPMC 队列）
+//   - seq == pos      ：槽位空闲，可由位置 pos 的生产者写入
+//   - seq == pos + 1  ：槽位已写入，可由位置 pos 的消费者读取
+type ringCell struct {
+	seq atomic.Uint64
+	msg []byte
+}
+
+// closedBit enq 的最高位：置位后生产者无法再占用槽位。关闭与占用槽位作用于同一个字，
+// 因此每个生产者要么在关闭前占到槽位（消息会被排空），要么看到关闭而失败
+const closedBit = 1 << 63
+
+type ring struct {
+	cells []ringCell
+	size  uint64
+	_     [64]byte      // 填充，避免 enq 与 deq 伪共享
+	enq   atomic.Uint64 // 下一个入队位置，最高位为关闭标记
+	_     [56]byte
+	deq   atomic.Uint64 // 下一个出队位置
+	done  chan struct{} // Destroy 时关闭
+}
+
+func newRing(capacity int) *ring {
+	r := &ring{
+		cells: make([]ringCell, capacity),
+		size:  uint64(capacity),
+		done:  make(chan struct{}),
+	}
+	for i := range r.cells {
+		r.cells[i].seq.Store(uint64(i))
+	}
+	return r
+}
+
+// enqResult tryEnq 的结果
+type enqResult int
+
+const (
+	enqOK enqResult = iota
+	enqFull
+	enqClosed
+)
+
+func (r *ring) tryEnq(msg []byte) enqResult {
+	pos := r.enq.Load()
+	for {
+		if pos&closedBit != 0 {
+			return enqClosed
+		}
+		cell := &r.cells[pos%r.size]
+		seq := cell.seq.Load()
+		switch dif := int64(seq - pos); {
+		case dif == 0:
+			if r.enq.CompareAndSwap(pos, pos+1) {
+				cell.msg = msg
+				cell.seq.Store(pos + 1) // 发布
+				return enqOK
+			}
+			pos = r.enq.Load() // 其他生产者抢先或队列已关闭
+		case dif < 0: // 槽位还未被消费：队列已满
+			return enqFull
+		default: // 其他生产者抢先占用了 pos
+			pos = r.enq.Load()
+		}
+	}
+}
+
+func (r *ring) tryDeq() ([]byte, bool) {
+	pos := r.deq.Load()
+	for {
+		cell := &r.cells[pos%r.size]
+		seq := cell.seq.Load()
+		switch dif := int64(seq - (pos + 1)); {
+		case dif == 0:
+			if r.deq.CompareAndSwap(pos, pos+1) {
+				msg := cell.msg
+				cell.msg = nil               // 清零，避免内存保持
+				cell.seq.Store(pos + r.size) // 归还给下一轮的生产者
+				return msg, true
+			}
+			pos = r.deq.Load()
+		case dif < 0: // 槽位还未写入：队列为空
+			return nil, false
+		default: // 其他消费者抢先取走了 pos
+			pos = r.deq.Load()
+		}
+	}
+}
+
+// close 禁止后续入队并唤醒等待的消费者
+func (r *ring) close() {
+	r.enq.Or(closedBit)
+	close(r.done)
+}
+
+// drain 关闭后取出一条剩余消息：已占用槽位但尚未发布的生产者很快会完成，等待它发布
+// 返回 false 表示所有在关闭前入队的消息都已被取走
+func (r *ring) drain() ([]byte, bool) {
+	end := r.enq.Load() &^ closedBit
+	for {
+		if msg, ok := r.tryDeq(); ok {
+			return msg, true
+		}
+		if r.deq.Load() >= end {
+			return nil, false
+		}
+		runtime.Gosched()
+	}
+}
+
+func (r *ring) len() int {
+	n := int64(r.enq.Load()&^closedBit - r.deq.Load())
+	return int(max(0, min(n, int64(r.size))))
+}
+
+// RingMQ 是基于无锁有界环形队列（Vyukov MPMC）的 MessageQueueInterface 实现
+// Enq/Deq 热路径上没有互斥锁，只有 Renew/Destroy 这类生命周期操作会加锁
+// Destroy 通过 enq 的关闭标记与生产者同步：Enq 成功的消息一定能被消费者取出
+type RingMQ struct {
+	r        atomic.Pointer[ring]
+	live     atomic.Bool
+	notify   chan struct{} // 容量为 1 的唤醒信号，供阻塞的 Deq 等待
+	mutex    sync.Mutex    // 只保护 Renew/Destroy
+	deviceId string
+	capacity int
+}
+
+var _ MessageQueueInterface = (*RingMQ)(nil)
+
+// NewRingMQ 创建一个 RingMQ，capacity 小于 1 时按 1 处理
+func NewRingMQ(capacity int, deviceId string) *RingMQ {
+	if capacity < 1 {
+		capacity = 1
+	}
+	q := &RingMQ{
+		notify:   make(chan struct{}, 1),
+		deviceId: deviceId,
+		capacity: capacity,
+	}
+	q.r.Store(newRing(capacity))
+	q.live.Store(true)
+	return q
+}
+
+// signal 非阻塞地发出唤醒信号，已有未消费的信号时直接丢弃
+func (q *RingMQ) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (q *RingMQ) Enq(msg []byte) error {
+	for {
+		r := q.r.Load()
+		switch r.tryEnq(msg) {
+		case enqOK:
+			q.signal()
+			return nil
+		case enqFull:
+			return fmt.Errorf("MQ is full")
+		}
+		// 环形队列已关闭：期间被 Renew 替换时改写新的环形队列
+		if q.r.Load() == r {
+			return fmt.Errorf("insert Msg to a dead MQ")
+		}
+	}
+}
+
+func (q *RingMQ) Deq(ctx context.Context) ([]byte, error) {
+	for {
+		r := q.r.Load()
+		if msg, ok := r.tryDeq(); ok {
+			// 多个消费者共用一个信号：还有剩余消息时把信号传递下去
+			if r.len() > 0 {
+				q.signal()
+			}
+			return msg, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("context done. Aborting deq")
+		case <-r.done:
+			// 与关闭的 channel 一致：已销毁的队列仍可取出剩余消息
+			if msg, ok := r.drain(); ok {
+				return msg, nil
+			}
+			return nil, fmt.Errorf("deq a closed MQ")
+		case <-q.notify:
+		}
+	}
+}
+
+func (q *RingMQ) Len() int {
+	return q.r.Load().len()
+}
+
+func (q *RingMQ) Clear() error {
+	r := q.r.Load()
+	for {
+		if _, ok := r.tryDeq(); !ok {
+			return nil
+		}
+	}
+}
+
+func (q *RingMQ) IsLive() bool {
+	return q.live.Load()
+}
+
+func (q *RingMQ) Renew() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if !q.live.Load() {
+		q.r.Store(newRing(q.capacity))
+		q.live.Store(true)
+	}
+}
+
+func (q *RingMQ) Destroy() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.live.Load() {
+		q.live.Store(false)
+		q.r.Load().close()
+	}
+}
@@ -0,0 +1,312 @@
+package pq
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// AgingFunc 根据基础优先级和已等待时间计算有效优先级，数值越大越优先
+type AgingFunc func(base float64, waited time.Duration) float64
+
+// LinearAging 有效优先级随等待时间线性增长：base + rate*秒数
+func LinearAging(rate float64) AgingFunc {
+	return func(base float64, waited time.Duration) float64 {
+		return base + rate*waited.Seconds()
+	}
+}
+
+// StepAging 每等待 step 时间，有效优先级提升 boost；step 必须为正数，否则 panic
+func StepAging(step time.Duration, boost float64) AgingFunc {
+	if step <= 0 {
+		panic("pq: StepAging step must be positive")
+	}
+	return func(base float64, waited time.Duration) float64 {
+		return base + boost*float64(waited/step)
+	}
+}
+
+// ExponentialAging 有效优先级每等待 halfLife 时间翻倍：base * 2^(waited/halfLife)
+// 要求 base 为正数；halfLife 必须为正数，否则 panic
+func ExponentialAging(halfLife time.Duration) AgingFunc {
+	if halfLife <= 0 {
+		panic("pq: ExponentialAging halfLife must be positive")
+	}
+	return func(base float64, waited time.Duration) float64 {
+		return base * math.Exp2(float64(waited)/float64(halfLife))
+	}
+}
+
+type agingItem[T any] struct {
+	item     T
+	priority float64
+	enqueued time.Time
+	key      float64 // 线性老化时不随时间变化的排序键
+	seq      uint64  // 入队序号，键相同时先入先出
+}
+
+// AgingQueue 是防饥饿的优先队列：元素的有效优先级随等待时间增长
+// 有效优先级相同时按入队顺序出队
+// 通用的 AgingFunc 无法预先排序，出队需扫描全部元素；线性老化请使用 NewLinearAgingQueue（基于堆）
+type AgingQueue[T any] struct {
+	mu    sync.Mutex
+	items []agingItem[T]
+	aging AgingFunc
+	now   func() time.Time
+
+	// 线性老化：有效优先级 base + rate*(now-enqueued) 的相对大小只取决于 base - rate*enqueued，
+	// 因此可按这个固定键用堆排序
+	heap   *minMaxHeap[agingItem[T]]
+	rate   float64
+	origin time.Time // 计算排序键的时间原点，首次入队时确定，避免绝对时间带来的浮点精度损失
+	seq    uint64
+}
+
+// NewAgingQueue 创建一个新的老化优先队列
+// 时间复杂度: O(1)
+func NewAgingQueue[T any](aging AgingFunc) (*AgingQueue[T], error) {
+	if aging == nil {
+		return nil, errors.New("aging function cannot be nil")
+	}
+	return &AgingQueue[T]{aging: aging, now: time.Now}, nil
+}
+
+// NewLinearAgingQueue 创建按 LinearAging(rate) 老化的优先队列，出队为 O(logN)
+// 时间复杂度: O(1)
+func NewLinearAgingQueue[T any](rate float64) *AgingQueue[T] {
+	return &AgingQueue[T]{
+		aging: LinearAging(rate),
+		now:   time.Now,
+		rate:  rate,
+		heap: newMinMaxHeap(0, func(a, b agingItem[T]) bool {
+			if a.key != b.key {
+				return a.key > b.key
+			}
+			return a.seq < b.seq
+		}),
+	}
+}
+
+// Len 返回队列当前长度
+// 时间复杂度: O(1)
+func (q *AgingQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.heap != nil {
+		return q.heap.len()
+	}
+	return len(q.items)
+}
+
+// Enqueue 以基础优先级 priority 添加元素
+// 时间复杂度: O(1)，线性老化队列为 O(logN)
+func (q *AgingQueue[T]) Enqueue(item T, priority float64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	it := agingItem[T]{item: item, priority: priority, enqueued: q.now()}
+	if q.heap == nil {
+		q.items = append(q.items, it)
+		return
+	}
+	if q.origin.IsZero() {
+		q.origin = it.enqueued
+	}
+	it.key = priority - q.rate*it.enqueued.Sub(q.origin).Seconds()
+	it.seq = q.seq
+	q.seq++
+	q.heap.push(it)
+}
+
+// Dequeue 移除并返回当前有效优先级最高的元素，以及它的等待时间
+// 有效优先级随时间变化，通用 AgingFunc 每次出队都需重新计算所有元素
+// 时间复杂度: O(N)，线性老化队列为 O(logN)
+func (q *AgingQueue[T]) Dequeue() (T, time.Duration, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var zero T
+	now := q.now()
+	if q.heap != nil {
+		if q.heap.len() == 0 {
+			return zero, 0, errors.New("queue is empty")
+		}
+		it := q.heap.popMin()
+		return it.item, now.Sub(it.enqueued), nil
+	}
+	if len(q.items) == 0 {
+		return zero, 0, errors.New("queue is empty")
+	}
+
+	best, bestScore := 0, math.Inf(-1)
+	for i, it := range q.items {
+		// items 按入队顺序排列，严格大于保证了同分时先入先出
+		if score := q.aging(it.priority, now.Sub(it.enqueued)); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+
+	it := q.items[best]
+	copy(q.items[best:], q.items[best+1:])
+	q.items[len(q.items)-1] = agingItem[T]{} // 清零，避免内存保持
+	q.items = q.items[:len(q.items)-1]
+	return it.item, now.Sub(it.enqueued), nil
+}
+
+// LevelStats 多级反馈队列中某一级的统计信息
+type LevelStats struct {
+	Len       int           // 当前排队数
+	Dequeued  int           // 累计出队数
+	TotalWait time.Duration // 已出队元素的累计等待时间
+	MaxWait   time.Duration // 已出队元素的最长等待时间
+}
+
+// AvgWait 返回已出队元素的平均等待时间
+func (s LevelStats) AvgWait() time.Duration {
+	if s.Dequeued == 0 {
+		return 0
+	}
+	return s.TotalWait / time.Duration(s.Dequeued)
+}
+
+type mlfqLevel[T any] struct {
+	items []agingItem[T]
+	head  int
+	stats LevelStats
+}
+
+func (l *mlfqLevel[T]) len() int { return len(l.items) - l.head }
+
+func (l *mlfqLevel[T]) push(it agingItem[T]) { l.items = append(l.items, it) }
+
+func (l *mlfqLevel[T]) pop() agingItem[T] {
+	it := l.items[l.head]
+	l.items[l.head] = agingItem[T]{} // 清零，避免内存保持
+	l.head++
+	// 已出队部分超过一半时压缩，避免容量泄漏
+	if l.head > len(l.items)/2 {
+		n := copy(l.items, l.items[l.head:])
+		clear(l.items[n:])
+		l.items = l.items[:n]
+		l.head = 0
+	}
+	return it
+}
+
+// MLFQ 多级反馈队列：第 0 级优先级最高，同级先入先出
+// 用满时间片的任务通过 Requeue 降级；每隔 boostInterval 把所有任务提升回第 0 级，避免饥饿
+type MLFQ[T any] struct {
+	mu            sync.Mutex
+	levels        []*mlfqLevel[T]
+	boostInterval time.Duration
+	lastBoost     time.Time
+	now           func() time.Time
+}
+
+// NewMLFQ 创建一个有 levels 级的多级反馈队列
+// boostInterval为0表示不做周期性提升
+// 时间复杂度: O(levels)
+func NewMLFQ[T any](levels int, boostInterval time.Duration) (*MLFQ[T], error) {
+	if levels <= 0 {
+		return nil, errors.New("levels must be positive")
+	}
+	if boostInterval < 0 {
+		return nil, errors.New("boost interval must be non-negative")
+	}
+	q := &MLFQ[T]{
+		levels:        make([]*mlfqLevel[T], levels),
+		boostInterval: boostInterval,
+		now:           time.Now,
+	}
+	for i := range q.levels {
+		q.levels[i] = &mlfqLevel[T]{}
+	}
+	q.lastBoost = q.now()
+	return q, nil
+}
+
+// Len 返回所有级别的排队总数
+// 时间复杂度: O(levels)
+func (q *MLFQ[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for _, l := range q.levels {
+		n += l.len()
+	}
+	return n
+}
+
+// Enqueue 把新任务放入第 0 级
+// 时间复杂度: O(1)
+func (q *MLFQ[T]) Enqueue(item T) {
+	q.EnqueueAt(item, 0)
+}
+
+// EnqueueAt 把任务放入指定级别，级别越界时钳制到合法范围
+// 时间复杂度: O(1)
+func (q *MLFQ[T]) EnqueueAt(item T, level int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	level = max(0, min(level, len(q.levels)-1))
+	q.levels[level].push(agingItem[T]{item: item, enqueued: q.now()})
+}
+
+// Requeue 任务运行一个时间片后放回队列：demote 为 true（用满时间片）时降一级
+// 时间复杂度: O(1)
+func (q *MLFQ[T]) Requeue(item T, level int, demote bool) {
+	if demote {
+		level++
+	}
+	q.EnqueueAt(item, level)
+}
+
+// Dequeue 移除并返回最高非空级别的队首任务及其所在级别
+// 时间复杂度: O(levels)，发生提升时为 O(N)
+func (q *MLFQ[T]) Dequeue() (T, int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	if q.boostInterval > 0 && now.Sub(q.lastBoost) >= q.boostInterval {
+		q.boost()
+		q.lastBoost = now
+	}
+
+	for i, l := range q.levels {
+		if l.len() == 0 {
+			continue
+		}
+		it := l.pop()
+		waited := now.Sub(it.enqueued)
+		l.stats.Dequeued++
+		l.stats.TotalWait += waited
+		l.stats.MaxWait = max(l.stats.MaxWait, waited)
+		return it.item, i, nil
+	}
+	var zero T
+	return zero, 0, errors.New("queue is empty")
+}
+
+// boost 从第 1 级开始逐级把任务追加到第 0 级队尾（同级内先入先出，级别高的排在前面），保留其入队时间以便统计等待时长
+func (q *MLFQ[T]) boost() {
+	top := q.levels[0]
+	for _, l := range q.levels[1:] {
+		for l.len() > 0 {
+			top.push(l.pop())
+		}
+	}
+}
+
+// Stats 返回各级别的统计信息
+// 时间复杂度: O(levels)
+func (q *MLFQ[T]) Stats() []LevelStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := make([]LevelStats, len(q.levels))
+	for i, l := range q.levels {
+		stats[i] = l.stats
+		stats[i].Len = l.len()
+	}
+	return stats
+}
@@ -0,0 +1,160 @@
+package pq
+
+import (
+	"errors"
+	"slices"
+)
+
+// MinMaxPriorityQueue 是双端优先队列（最小-最大堆），可同时取最小和最大元素
+// capacity>0 时为有界模式：超出容量时淘汰最大元素，并通过 OnEvict 回调通知调用方
+type MinMaxPriorityQueue[T any] struct {
+	heap     *minMaxHeap[T]
+	capacity int
+	less     func(a, b T) bool // 比较函数，返回true表示a小于b
+	onEvict  func(item T)
+}
+
+// NewMinMaxPriorityQueue 创建一个新的双端优先队列
+// capacity为0表示没有容量限制
+// 时间复杂度: O(1)
+func NewMinMaxPriorityQueue[T any](capacity int, less func(a, b T) bool) (*MinMaxPriorityQueue[T], error) {
+	if capacity < 0 {
+		return nil, errors.New("capacity must be non-negative")
+	}
+	if less == nil {
+		return nil, errors.New("less function cannot be nil")
+	}
+	return &MinMaxPriorityQueue[T]{
+		heap:     newMinMaxHeap(capacity, less),
+		capacity: capacity,
+		less:     less,
+	}, nil
+}
+
+// OnEvict 设置淘汰回调，有界模式下被丢弃的元素（可能是新元素本身）会传给 fn
+func (pq *MinMaxPriorityQueue[T]) OnEvict(fn func(item T)) {
+	pq.onEvict = fn
+}
+
+// Len 返回队列当前长度
+// 时间复杂度: O(1)
+func (pq *MinMaxPriorityQueue[T]) Len() int {
+	return pq.heap.len()
+}
+
+// Enqueue 添加元素，超出容量时淘汰最大元素
+// 返回被淘汰的元素以及是否发生了淘汰
+// 时间复杂度: O(logN)
+func (pq *MinMaxPriorityQueue[T]) Enqueue(item T) (T, bool) {
+	var zero T
+	if pq.capacity > 0 && pq.heap.len() == pq.capacity {
+		evicted := item
+		if pq.less(item, pq.heap.data[pq.heap.maxIndex()]) {
+			evicted = pq.heap.popMax()
+			pq.heap.push(item)
+		}
+		if pq.onEvict != nil {
+			pq.onEvict(evicted)
+		}
+		return evicted, true
+	}
+	pq.heap.push(item)
+	return zero, false
+}
+
+// PeekMin 返回最小元素但不移除
+// 时间复杂度: O(1)
+func (pq *MinMaxPriorityQueue[T]) PeekMin() (T, error) {
+	if pq.heap.len() == 0 {
+		var zero T
+		return zero, errors.New("queue is empty")
+	}
+	return pq.heap.data[pq.heap.minIndex()], nil
+}
+
+// PeekMax 返回最大元素但不移除
+// 时间复杂度: O(1)
+func (pq *MinMaxPriorityQueue[T]) PeekMax() (T, error) {
+	if pq.heap.len() == 0 {
+		var zero T
+		return zero, errors.New("queue is empty")
+	}
+	return pq.heap.data[pq.heap.maxIndex()], nil
+}
+
+// PopMin 移除并返回最小元素
+// 时间复杂度: O(logN)
+func (pq *MinMaxPriorityQueue[T]) PopMin() (T, error) {
+	if pq.heap.len() == 0 {
+		var zero T
+		return zero, errors.New("queue is empty")
+	}
+	return pq.heap.popMin(), nil
+}
+
+// PopMax 移除并返回最大元素
+// 时间复杂度: O(logN)
+func (pq *MinMaxPriorityQueue[T]) PopMax() (T, error) {
+	if pq.heap.len() == 0 {
+		var zero T
+		return zero, errors.New("queue is empty")
+	}
+	return pq.heap.popMax(), nil
+}
+
+// TopK 流式维护最好的 k 个元素，适用于排行榜和高频项统计
+type TopK[T any] struct {
+	pq     *MinMaxPriorityQueue[T]
+	better func(a, b T) bool
+}
+
+// NewTopK 创建一个保留最好 k 个元素的 TopK
+// 时间复杂度: O(1)
+func NewTopK[T any](k int, better func(a, b T) bool) (*TopK[T], error) {
+	if k <= 0 {
+		return nil, errors.New("k must be positive")
+	}
+	pq, err := NewMinMaxPriorityQueue(k, better)
+	if err != nil {
+		return nil, err
+	}
+	return &TopK[T]{pq: pq, better: better}, nil
+}
+
+// Offer 提交一个元素；若因此有元素跌出前 k 名，返回该元素
+// 时间复杂度: O(logK)
+func (t *TopK[T]) Offer(item T) (T, bool) {
+	return t.pq.Enqueue(item)
+}
+
+// Len 返回当前保留的元素个数
+func (t *TopK[T]) Len() int { return t.pq.Len() }
+
+// Best 返回当前最好的元素
+// 时间复杂度: O(1)
+func (t *TopK[T]) Best() (T, error) { return t.pq.PeekMin() }
+
+// Threshold 返回第 k 名（当前保留元素中最差的一个），新元素需优于它才能进入
+// 时间复杂度: O(1)
+func (t *TopK[T]) Threshold() (T, error) { return t.pq.PeekMax() }
+
+// Items 返回按从好到差排列的元素副本
+// 时间复杂度: O(KlogK)
+func (t *TopK[T]) Items() []T {
+	return sortedBy(t.pq.heap.data, t.better)
+}
+
+// sortedBy 返回按 better 从好到差排序的副本
+func sortedBy[T any](items []T, better func(a, b T) bool) []T {
+	cp := slices.Clone(items)
+	slices.SortFunc(cp, func(a, b T) int {
+		switch {
+		case better(a, b):
+			return -1
+		case better(b, a):
+			return 1
+		}
+		return 0
+	})
+	return cp
+}
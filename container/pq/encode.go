@@ -0,0 +1,166 @@
+package pq
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+)
+
+// snapshot 是优先队列的持久化格式，Items 按优先级从高到低排列
+// better 函数无法序列化，恢复时由调用方提供
+type snapshot[T any] struct {
+	Capacity int `json:"capacity"`
+	Items    []T `json:"items"`
+}
+
+func encodeGob[T any](s snapshot[T]) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeGob[T any](b []byte) (snapshot[T], error) {
+	var s snapshot[T]
+	err := gob.NewDecoder(bytes.NewReader(b)).Decode(&s)
+	return s, err
+}
+
+// restore 用调用方的 better 函数按快照重建队列内容
+func (pq *PriorityQueue[T]) restore(s snapshot[T]) error {
+	if pq.better == nil {
+		return errors.New("better function cannot be nil, create the queue with NewPriorityQueue first")
+	}
+	if s.Capacity < 0 {
+		return errors.New("capacity must be non-negative")
+	}
+	pq.capacity = s.Capacity
+	pq.data = make([]T, 0, max(s.Capacity, len(s.Items)))
+	for _, item := range s.Items {
+		pq.Enqueue(item)
+	}
+	return nil
+}
+
+// MarshalJSON 将队列编码为 {"capacity":N,"items":[...]}
+func (pq *PriorityQueue[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(snapshot[T]{Capacity: pq.capacity, Items: pq.Snapshot()})
+}
+
+// UnmarshalJSON 从快照恢复队列，接收者必须由 NewPriorityQueue 创建以提供 better 函数
+// 时间复杂度: O(NlogN)
+func (pq *PriorityQueue[T]) UnmarshalJSON(b []byte) error {
+	var s snapshot[T]
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return pq.restore(s)
+}
+
+// GobEncode 实现 gob.GobEncoder，内容与 MarshalJSON 相同
+func (pq *PriorityQueue[T]) GobEncode() ([]byte, error) {
+	return encodeGob(snapshot[T]{Capacity: pq.capacity, Items: pq.Snapshot()})
+}
+
+// GobDecode 实现 gob.GobDecoder，接收者必须由 NewPriorityQueue 创建以提供 better 函数
+func (pq *PriorityQueue[T]) GobDecode(b []byte) error {
+	s, err := decodeGob[T](b)
+	if err != nil {
+		return err
+	}
+	return pq.restore(s)
+}
+
+// RestorePriorityQueue 从 MarshalJSON 生成的快照创建优先队列
+func RestorePriorityQueue[T any](b []byte, better func(a, b T) bool) (*PriorityQueue[T], error) {
+	pq, err := NewPriorityQueue(0, better)
+	if err != nil {
+		return nil, err
+	}
+	if err := pq.UnmarshalJSON(b); err != nil {
+		return nil, err
+	}
+	return pq, nil
+}
+
+// restore 用调用方的 better 函数按快照重建堆
+func (pq *SyncPriorityQueue[T]) restore(s snapshot[T]) error {
+	if pq.better == nil {
+		return errors.New("better function cannot be nil, create the queue with NewSyncPriorityQueue first")
+	}
+	if s.Capacity < 0 {
+		return errors.New("capacity must be non-negative")
+	}
+	// 先在锁外建好完整的堆，再一次性替换，其他 goroutine 不会看到恢复了一半的队列
+	h := newMinMaxHeap(max(s.Capacity, len(s.Items)), pq.better)
+	for _, item := range s.Items {
+		if s.Capacity > 0 && h.len() == s.Capacity {
+			if !pq.better(item, h.data[h.maxIndex()]) {
+				continue
+			}
+			h.popMax()
+		}
+		h.push(item)
+	}
+
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	pq.capacity = s.Capacity
+	pq.heap = h
+	// 唤醒阻塞在 DequeueContext 上的等待者
+	if pq.ready != nil && h.len() > 0 {
+		close(pq.ready)
+		pq.ready = nil
+	}
+	return nil
+}
+
+// snapshot 在同一次加锁中读取容量和元素，避免与并发的 restore 交错
+func (pq *SyncPriorityQueue[T]) snapshot() snapshot[T] {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return snapshot[T]{Capacity: pq.capacity, Items: sortedBy(pq.heap.data, pq.better)}
+}
+
+// MarshalJSON 将队列编码为 {"capacity":N,"items":[...]}
+func (pq *SyncPriorityQueue[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pq.snapshot())
+}
+
+// UnmarshalJSON 从快照恢复队列，接收者必须由 NewSyncPriorityQueue 创建以提供 better 函数
+// 时间复杂度: O(NlogN)
+func (pq *SyncPriorityQueue[T]) UnmarshalJSON(b []byte) error {
+	var s snapshot[T]
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return pq.restore(s)
+}
+
+// GobEncode 实现 gob.GobEncoder，内容与 MarshalJSON 相同
+func (pq *SyncPriorityQueue[T]) GobEncode() ([]byte, error) {
+	return encodeGob(pq.snapshot())
+}
+
+// GobDecode 实现 gob.GobDecoder，接收者必须由 NewSyncPriorityQueue 创建以提供 better 函数
+func (pq *SyncPriorityQueue[T]) GobDecode(b []byte) error {
+	s, err := decodeGob[T](b)
+	if err != nil {
+		return err
+	}
+	return pq.restore(s)
+}
+
+// RestoreSyncPriorityQueue 从 MarshalJSON 生成的快照创建并发安全优先队列
+func RestoreSyncPriorityQueue[T any](b []byte, better func(a, b T) bool) (*SyncPriorityQueue[T], error) {
+	pq, err := NewSyncPriorityQueue(0, better)
+	if err != nil {
+		return nil, err
+	}
+	if err := pq.UnmarshalJSON(b); err != nil {
+		return nil, err
+	}
+	return pq, nil
+}
@@ -0,0 +1,186 @@
+package pq
+
+import "errors"
+
+// IndexedPriorityQueue 是按可比较键索引的优先队列，支持泛型
+// 每个元素都有唯一的 id，可以按 id 修改优先级、删除或查询
+type IndexedPriorityQueue[K comparable, T any] struct {
+	ids    []K       // 堆：按优先级排列的 id
+	items  map[K]T   // id -> 元素
+	pos    map[K]int // id -> 在 ids 中的下标
+	better func(a, b T) bool
+}
+
+// NewIndexedPriorityQueue 创建一个新的索引优先队列
+// 时间复杂度: O(1)
+func NewIndexedPriorityQueue[K comparable, T any](better func(a, b T) bool) (*IndexedPriorityQueue[K, T], error) {
+	if better == nil {
+		return nil, errors.New("better function cannot be nil")
+	}
+	return &IndexedPriorityQueue[K, T]{
+		items:  make(map[K]T),
+		pos:    make(map[K]int),
+		better: better,
+	}, nil
+}
+
+// Len 返回队列当前长度
+// 时间复杂度: O(1)
+func (pq *IndexedPriorityQueue[K, T]) Len() int {
+	return len(pq.ids)
+}
+
+// Contains 判断 id 是否在队列中
+// 时间复杂度: O(1)
+func (pq *IndexedPriorityQueue[K, T]) Contains(id K) bool {
+	_, ok := pq.pos[id]
+	return ok
+}
+
+// Get 返回 id 对应的元素
+// 时间复杂度: O(1)
+func (pq *IndexedPriorityQueue[K, T]) Get(id K) (T, bool) {
+	item, ok := pq.items[id]
+	return item, ok
+}
+
+// Enqueue 以 id 添加元素，id 已存在时返回错误
+// 时间复杂度: O(logN)
+func (pq *IndexedPriorityQueue[K, T]) Enqueue(id K, item T) error {
+	if pq.Contains(id) {
+		return errors.New("id already exists")
+	}
+	pq.ids = append(pq.ids, id)
+	pq.items[id] = item
+	pq.pos[id] = len(pq.ids) - 1
+	pq.up(len(pq.ids) - 1)
+	return nil
+}
+
+// Update 替换 id 对应的元素并调整其位置，id 不存在时返回错误
+// 时间复杂度: O(logN)
+func (pq *IndexedPriorityQueue[K, T]) Update(id K, item T) error {
+	i, ok := pq.pos[id]
+	if !ok {
+		return errors.New("id not found")
+	}
+	pq.items[id] = item
+	pq.fix(i)
+	return nil
+}
+
+// Upsert 存在则更新，不存在则添加
+// 时间复杂度: O(logN)
+func (pq *IndexedPriorityQueue[K, T]) Upsert(id K, item T) {
+	if pq.Contains(id) {
+		pq.Update(id, item)
+		return
+	}
+	pq.Enqueue(id, item)
+}
+
+// Remove 删除并返回 id 对应的元素
+// 时间复杂度: O(logN)
+func (pq *IndexedPriorityQueue[K, T]) Remove(id K) (T, bool) {
+	i, ok := pq.pos[id]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	item := pq.items[id]
+	pq.removeAt(i)
+	return item, true
+}
+
+// Dequeue 移除并返回优先级最高的元素及其 id
+// 时间复杂度: O(logN)
+func (pq *IndexedPriorityQueue[K, T]) Dequeue() (K, T, error) {
+	if len(pq.ids) == 0 {
+		var zeroK K
+		var zero T
+		return zeroK, zero, errors.New("queue is empty")
+	}
+	id := pq.ids[0]
+	item := pq.items[id]
+	pq.removeAt(0)
+	return id, item, nil
+}
+
+// Peek 返回优先级最高的元素及其 id 但不移除
+// 时间复杂度: O(1)
+func (pq *IndexedPriorityQueue[K, T]) Peek() (K, T, error) {
+	if len(pq.ids) == 0 {
+		var zeroK K
+		var zero T
+		return zeroK, zero, errors.New("queue is empty")
+	}
+	id := pq.ids[0]
+	return id, pq.items[id], nil
+}
+
+// removeAt 删除堆中下标 i 的元素
+func (pq *IndexedPriorityQueue[K, T]) removeAt(i int) {
+	id := pq.ids[i]
+	last := len(pq.ids) - 1
+	if i != last {
+		pq.swap(i, last)
+	}
+	var zeroK K
+	pq.ids[last] = zeroK // 清零，避免内存保持
+	pq.ids = pq.ids[:last]
+	delete(pq.items, id)
+	delete(pq.pos, id)
+	if i < last {
+		pq.fix(i)
+	}
+}
+
+func (pq *IndexedPriorityQueue[K, T]) before(i, j int) bool {
+	return pq.better(pq.items[pq.ids[i]], pq.items[pq.ids[j]])
+}
+
+func (pq *IndexedPriorityQueue[K, T]) swap(i, j int) {
+	pq.ids[i], pq.ids[j] = pq.ids[j], pq.ids[i]
+	pq.pos[pq.ids[i]] = i
+	pq.pos[pq.ids[j]] = j
+}
+
+// fix 元素变化后恢复堆性质
+func (pq *IndexedPriorityQueue[K, T]) fix(i int) {
+	if !pq.down(i) {
+		pq.up(i)
+	}
+}
+
+func (pq *IndexedPriorityQueue[K, T]) up(i int) {
+	for i > 0 {
+		p := (i - 1) / 2
+		if !pq.before(i, p) {
+			return
+		}
+		pq.swap(i, p)
+		i = p
+	}
+}
+
+// down 下沉元素，返回是否发生了移动
+func (pq *IndexedPriorityQueue[K, T]) down(i int) bool {
+	start := i
+	n := len(pq.ids)
+	for {
+		l := 2*i + 1
+		if l >= n {
+			break
+		}
+		c := l
+		if r := l + 1; r < n && pq.before(r, l) {
+			c = r
+		}
+		if !pq.before(c, i) {
+			break
+		}
+		pq.swap(i, c)
+		i = c
+	}
+	return i > start
+}
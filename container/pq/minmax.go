@@ -0,0 +1,160 @@
+package pq
+
+import "math/bits"
+
+// minMaxHeap 最小-最大堆：偶数层为最小层，奇数层为最大层
+// 堆顶为最小元素，堆顶的两个孩子中较大者为最大元素
+// less(a, b) 返回 true 表示 a 比 b 小
+type minMaxHeap[T any] struct {
+	data []T
+	less func(a, b T) bool
+}
+
+func newMinMaxHeap[T any](capacity int, less func(a, b T) bool) *minMaxHeap[T] {
+	return &minMaxHeap[T]{
+		data: make([]T, 0, capacity),
+		less: less,
+	}
+}
+
+func (h *minMaxHeap[T]) len() int { return len(h.data) }
+
+// isMinLevel 判断下标 i 是否位于最小层
+func isMinLevel(i int) bool {
+	return (bits.Len(uint(i+1))-1)%2 == 0
+}
+
+func (h *minMaxHeap[T]) swap(i, j int) { h.data[i], h.data[j] = h.data[j], h.data[i] }
+
+// push 插入元素
+// 时间复杂度: O(logN)
+func (h *minMaxHeap[T]) push(item T) {
+	h.data = append(h.data, item)
+	h.bubbleUp(len(h.data) - 1)
+}
+
+// minIndex 返回最小元素下标，调用方需保证堆非空
+func (h *minMaxHeap[T]) minIndex() int { return 0 }
+
+// maxIndex 返回最大元素下标，调用方需保证堆非空
+func (h *minMaxHeap[T]) maxIndex() int {
+	switch len(h.data) {
+	case 1:
+		return 0
+	case 2:
+		return 1
+	}
+	if h.less(h.data[1], h.data[2]) {
+		return 2
+	}
+	return 1
+}
+
+// removeAt 移除并返回下标 i 处的元素（仅用于堆顶的最小/最大位置）
+// 时间复杂度: O(logN)
+func (h *minMaxHeap[T]) removeAt(i int) T {
+	var zero T
+	item := h.data[i]
+	last := len(h.data) - 1
+	h.data[i] = h.data[last]
+	h.data[last] = zero // 清零，避免内存保持
+	h.data = h.data[:last]
+	if i < len(h.data) {
+		h.trickleDown(i)
+	}
+	return item
+}
+
+// popMin 移除并返回最小元素
+func (h *minMaxHeap[T]) popMin() T { return h.removeAt(h.minIndex()) }
+
+// popMax 移除并返回最大元素
+func (h *minMaxHeap[T]) popMax() T { return h.removeAt(h.maxIndex()) }
+
+// clear 清空堆并清零底层数组
+func (h *minMaxHeap[T]) clear() {
+	clear(h.data)
+	h.data = h.data[:0]
+}
+
+func (h *minMaxHeap[T]) bubbleUp(i int) {
+	if i == 0 {
+		return
+	}
+	p := (i - 1) / 2
+	if isMinLevel(i) {
+		if h.less(h.data[p], h.data[i]) {
+			h.swap(i, p)
+			h.bubbleUpMax(p)
+		} else {
+			h.bubbleUpMin(i)
+		}
+	} else {
+		if h.less(h.data[i], h.data[p]) {
+			h.swap(i, p)
+			h.bubbleUpMin(p)
+		} else {
+			h.bubbleUpMax(i)
+		}
+	}
+}
+
+func (h *minMaxHeap[T]) bubbleUpMin(i int) {
+	for i > 2 {
+		g := ((i-1)/2 - 1) / 2
+		if !h.less(h.data[i], h.data[g]) {
+			return
+		}
+		h.swap(i, g)
+		i = g
+	}
+}
+
+func (h *minMaxHeap[T]) bubbleUpMax(i int) {
+	for i > 2 {
+		g := ((i-1)/2 - 1) / 2
+		if !h.less(h.data[g], h.data[i]) {
+			return
+		}
+		h.swap(i, g)
+		i = g
+	}
+}
+
+func (h *minMaxHeap[T]) trickleDown(i int) {
+	if isMinLevel(i) {
+		h.trickleDownWith(i, h.less)
+	} else {
+		h.trickleDownWith(i, func(a, b T) bool { return h.less(b, a) })
+	}
+}
+
+// trickleDownWith 按 before 的顺序下沉：最小层使用 less，最大层使用反向比较
+func (h *minMaxHeap[T]) trickleDownWith(i int, before func(a, b T) bool) {
+	n := len(h.data)
+	for {
+		// 在孩子和孙子中找到最靠前的元素
+		m := -1
+		for _, c := range [...]int{2*i + 1, 2*i + 2, 4*i + 3, 4*i + 4, 4*i + 5, 4*i + 6} {
+			if c >= n {
+				break
+			}
+			if m < 0 || before(h.data[c], h.data[m]) {
+				m = c
+			}
+		}
+		if m < 0 || !before(h.data[m], h.data[i]) {
+			return
+		}
+		h.swap(m, i)
+		if m <= 2*i+2 { // 孩子：交换后即满足性质
+			return
+		}
+		// 孙子：还需与其父节点比较
+		p := (m - 1) / 2
+		if before(h.data[p], h.data[m]) {
+			h.swap(m, p)
+		}
+		i = m
+	}
+}
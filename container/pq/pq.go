@@ -2,6 +2,8 @@ package pq
 
 import (
 	"errors"
+	"iter"
+	"slices"
 )
 
 // PriorityQueue 是一个有容量限制的优先队列，支持泛型
@@ -109,4 +111,53 @@ func (pq *PriorityQueue[T]) Peek() (T, error) {
         return zero, errors.New("queue is empty")
     }
     return pq.data[0], nil
-}
\ No newline at end of file
+}
+
+// Snapshot 返回按优先级从高到低排列的元素副本
+// 时间复杂度: O(N)
+func (pq *PriorityQueue[T]) Snapshot() []T {
+	return slices.Clone(pq.data)
+}
+
+// All 返回按优先级从高到低遍历的迭代器，不修改队列
+// 遍历的是开始迭代时的快照，迭代过程中可以安全地修改队列
+func (pq *PriorityQueue[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, item := range pq.Snapshot() {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Drain 返回边遍历边出队的迭代器，提前结束遍历时未遍历的元素保留在队列中
+func (pq *PriorityQueue[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for pq.Len() > 0 {
+			item, _ := pq.Dequeue()
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Clear 清空队列
+// 时间复杂度: O(N)
+func (pq *PriorityQueue[T]) Clear() {
+	clear(pq.data) // 清零，避免内存保持
+	pq.data = pq.data[:0]
+}
+
+// Clone 返回队列的副本，与原队列共享 better 函数
+// 时间复杂度: O(N)
+func (pq *PriorityQueue[T]) Clone() *PriorityQueue[T] {
+	data := make([]T, len(pq.data), max(pq.capacity, len(pq.data)))
+	copy(data, pq.data)
+	return &PriorityQueue[T]{
+		data:     data,
+		capacity: pq.capacity,
+		better:   pq.better,
+	}
+}
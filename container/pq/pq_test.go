@@ -0,0 +1,504 @@
+package pq
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func less(a, b int) bool { return a < b }
+
+func TestMinMaxHeap(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	h := newMinMaxHeap(0, less)
+	var ref []int
+	for i := 0; i < 1000; i++ {
+		if r.Intn(3) > 0 || len(ref) == 0 {
+			v := r.Intn(100)
+			h.push(v)
+			ref = append(ref, v)
+			sort.Ints(ref)
+			continue
+		}
+		if r.Intn(2) == 0 {
+			if v := h.popMin(); v != ref[0] {
+				t.Fatalf("popMin = %d, want %d", v, ref[0])
+			}
+			ref = ref[1:]
+		} else {
+			if v := h.popMax(); v != ref[len(ref)-1] {
+				t.Fatalf("popMax = %d, want %d", v, ref[len(ref)-1])
+			}
+			ref = ref[:len(ref)-1]
+		}
+	}
+}
+
+func TestSyncPriorityQueueBounded(t *testing.T) {
+	q, err := NewSyncPriorityQueue(3, less)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []int{5, 1, 4, 2, 3} {
+		q.Enqueue(v)
+	}
+	if q.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", q.Len())
+	}
+	for _, want := range []int{1, 2, 3} {
+		if v, _ := q.Dequeue(); v != want {
+			t.Errorf("Dequeue = %d, want %d", v, want)
+		}
+	}
+	if _, err := q.Dequeue(); err == nil {
+		t.Error("Dequeue on empty queue should fail")
+	}
+}
+
+func TestSyncPriorityQueueDequeueContext(t *testing.T) {
+	q, _ := NewSyncPriorityQueue(0, less)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := q.DequeueContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+
+	const n = 100
+	var wg sync.WaitGroup
+	got := make(chan int, n)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+				v, err := q.DequeueContext(ctx)
+				cancel()
+				if err != nil {
+					return
+				}
+				got <- v
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		q.Enqueue(i)
+	}
+	wg.Wait()
+	if len(got) != n {
+		t.Fatalf("received %d items, want %d", len(got), n)
+	}
+}
+
+func benchmarkQueue(b *testing.B, enq func(int), deq func()) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1024; i++ {
+		enq(r.Int())
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		enq(r.Int())
+		deq()
+	}
+}
+
+func BenchmarkPriorityQueue(b *testing.B) {
+	q, _ := NewPriorityQueue(0, less)
+	benchmarkQueue(b, func(v int) { q.Enqueue(v) }, func() { q.Dequeue() })
+}
+
+func BenchmarkSyncPriorityQueue(b *testing.B) {
+	q, _ := NewSyncPriorityQueue(0, less)
+	benchmarkQueue(b, func(v int) { q.Enqueue(v) }, func() { q.Dequeue() })
+}
+
+func BenchmarkPriorityQueueBounded(b *testing.B) {
+	q, _ := NewPriorityQueue(1024, less)
+	benchmarkQueue(b, func(v int) { q.Enqueue(v) }, func() {})
+}
+
+func BenchmarkSyncPriorityQueueBounded(b *testing.B) {
+	q, _ := NewSyncPriorityQueue(1024, less)
+	benchmarkQueue(b, func(v int) { q.Enqueue(v) }, func() {})
+}
+
+func TestIndexedPriorityQueue(t *testing.T) {
+	q, err := NewIndexedPriorityQueue[string](less)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Enqueue("a", 5)
+	q.Enqueue("b", 3)
+	q.Enqueue("c", 8)
+	q.Enqueue("d", 1)
+	if err := q.Enqueue("a", 0); err == nil {
+		t.Error("Enqueue with duplicate id should fail")
+	}
+
+	q.Update("c", 0) // 提升优先级
+	if v, ok := q.Remove("d"); !ok || v != 1 {
+		t.Errorf("Remove(d) = %d, %v, want 1, true", v, ok)
+	}
+	if q.Contains("d") {
+		t.Error("d should have been removed")
+	}
+	if v, _ := q.Get("b"); v != 3 {
+		t.Errorf("Get(b) = %d, want 3", v)
+	}
+
+	var got []string
+	for q.Len() > 0 {
+		id, _, _ := q.Dequeue()
+		got = append(got, id)
+	}
+	want := []string{"c", "b", "a"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestMinMaxPriorityQueueEvict(t *testing.T) {
+	q, _ := NewMinMaxPriorityQueue(3, less)
+	var evicted []int
+	q.OnEvict(func(v int) { evicted = append(evicted, v) })
+	for _, v := range []int{5, 1, 4, 9, 2} {
+		q.Enqueue(v)
+	}
+	if len(evicted) != 2 || evicted[0] != 9 || evicted[1] != 5 {
+		t.Errorf("evicted = %v, want [9 5]", evicted)
+	}
+	if v, _ := q.PeekMax(); v != 4 {
+		t.Errorf("PeekMax = %d, want 4", v)
+	}
+	if v, _ := q.PopMin(); v != 1 {
+		t.Errorf("PopMin = %d, want 1", v)
+	}
+	if v, _ := q.PopMax(); v != 4 {
+		t.Errorf("PopMax = %d, want 4", v)
+	}
+}
+
+func TestTopK(t *testing.T) {
+	top, _ := NewTopK(3, func(a, b int) bool { return a > b })
+	for _, v := range []int{3, 10, 7, 1, 8, 2} {
+		top.Offer(v)
+	}
+	got := top.Items()
+	want := []int{10, 8, 7}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Items = %v, want %v", got, want)
+		}
+	}
+	if v, _ := top.Threshold(); v != 7 {
+		t.Errorf("Threshold = %d, want 7", v)
+	}
+}
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestAgingQueue(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	q, _ := NewAgingQueue[string](LinearAging(1))
+	q.now = clock.now
+
+	q.Enqueue("low", 0)
+	clock.advance(5 * time.Second)
+	q.Enqueue("high", 3)
+
+	// low 已等待 5s，有效优先级 5 > 3
+	v, waited, _ := q.Dequeue()
+	if v != "low" || waited != 5*time.Second {
+		t.Errorf("Dequeue = %q, %v, want low, 5s", v, waited)
+	}
+	if v, _, _ := q.Dequeue(); v != "high" {
+		t.Errorf("Dequeue = %q, want high", v)
+	}
+}
+
+// TestLinearAgingQueue 基于堆的线性老化队列与逐个计算有效优先级的通用实现出队顺序一致
+func TestLinearAgingQueue(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	heapQ := NewLinearAgingQueue[int](0.5)
+	heapQ.now = clock.now
+	scanQ, _ := NewAgingQueue[int](LinearAging(0.5))
+	scanQ.now = clock.now
+
+	for i := 0; i < 200; i++ {
+		p := float64((i * 37) % 11)
+		heapQ.Enqueue(i, p)
+		scanQ.Enqueue(i, p)
+		clock.advance(time.Duration(i%3) * 700 * time.Millisecond)
+		if i%4 == 3 {
+			a, wa, _ := heapQ.Dequeue()
+			b, wb, _ := scanQ.Dequeue()
+			if a != b || wa != wb {
+				t.Fatalf("step %d: heap Dequeue = %d (%v), scan Dequeue = %d (%v)", i, a, wa, b, wb)
+			}
+		}
+	}
+	for heapQ.Len() > 0 {
+		a, _, _ := heapQ.Dequeue()
+		b, _, _ := scanQ.Dequeue()
+		if a != b {
+			t.Fatalf("heap Dequeue = %d, scan Dequeue = %d", a, b)
+		}
+	}
+	if _, _, err := heapQ.Dequeue(); err == nil || scanQ.Len() != 0 {
+		t.Error("both queues should be empty")
+	}
+}
+
+func TestMLFQ(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	q, _ := NewMLFQ[string](3, 10*time.Second)
+	q.now = clock.now
+	q.lastBoost = clock.now()
+
+	q.EnqueueAt("batch", 2)
+	q.Enqueue("job")
+
+	v, level, _ := q.Dequeue()
+	if v != "job" || level != 0 {
+		t.Fatalf("Dequeue = %q@%d, want job@0", v, level)
+	}
+	q.Requeue(v, level, true)
+	if s := q.Stats(); s[1].Len != 1 || s[2].Len != 1 {
+		t.Fatalf("Stats = %+v", s)
+	}
+
+	// 到达提升周期后，第 1 级的 job 先于第 2 级的 batch 追加到第 0 级
+	clock.advance(10 * time.Second)
+	v, level, _ = q.Dequeue()
+	if v != "job" || level != 0 {
+		t.Errorf("Dequeue = %q@%d, want job@0", v, level)
+	}
+	v, level, _ = q.Dequeue()
+	if v != "batch" || level != 0 {
+		t.Errorf("Dequeue = %q@%d, want batch@0", v, level)
+	}
+	if s := q.Stats(); s[0].MaxWait != 10*time.Second || s[0].Dequeued != 3 {
+		t.Errorf("Stats[0] = %+v", s[0])
+	}
+}
+
+func TestPriorityQueueIter(t *testing.T) {
+	q, _ := NewPriorityQueue(0, less)
+	for _, v := range []int{3, 1, 2} {
+		q.Enqueue(v)
+	}
+	var got []int
+	for v := range q.All() {
+		got = append(got, v)
+	}
+	if len(got) != 3 || got[0] != 1 || got[2] != 3 || q.Len() != 3 {
+		t.Fatalf("All = %v, Len = %d", got, q.Len())
+	}
+
+	c := q.Clone()
+	for v := range q.Drain() {
+		if v == 2 {
+			break
+		}
+	}
+	if q.Len() != 1 || c.Len() != 3 {
+		t.Errorf("after Drain Len = %d, clone Len = %d, want 1, 3", q.Len(), c.Len())
+	}
+	c.Clear()
+	if c.Len() != 0 {
+		t.Errorf("after Clear Len = %d", c.Len())
+	}
+}
+
+func TestSyncPriorityQueueSnapshot(t *testing.T) {
+	q, _ := NewSyncPriorityQueue(0, less)
+	for _, v := range []int{5, 3, 9, 1} {
+		q.Enqueue(v)
+	}
+	snap := q.Snapshot()
+	want := []int{1, 3, 5, 9}
+	for i := range want {
+		if snap[i] != want[i] {
+			t.Fatalf("Snapshot = %v, want %v", snap, want)
+		}
+	}
+	var got []int
+	for v := range q.Drain() {
+		got = append(got, v)
+	}
+	if len(got) != 4 || got[3] != 9 || q.Len() != 0 {
+		t.Errorf("Drain = %v, Len = %d", got, q.Len())
+	}
+}
+
+func TestPriorityQueueJSON(t *testing.T) {
+	q, _ := NewPriorityQueue(3, less)
+	for _, v := range []int{4, 2, 9, 1} {
+		q.Enqueue(v)
+	}
+	b, err := json.Marshal(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"capacity":3,"items":[1,2,4]}` {
+		t.Errorf("Marshal = %s", b)
+	}
+
+	r, err := RestorePriorityQueue(b, less)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Enqueue(3) // 容量也被恢复，4 被丢弃
+	if got := r.Snapshot(); len(got) != 3 || got[2] != 3 {
+		t.Errorf("restored = %v, want [1 2 3]", got)
+	}
+
+	var zero PriorityQueue[int]
+	if err := json.Unmarshal(b, &zero); err == nil {
+		t.Error("Unmarshal without better function should fail")
+	}
+}
+
+func TestSyncPriorityQueueGob(t *testing.T) {
+	q, _ := NewSyncPriorityQueue(0, less)
+	for _, v := range []int{5, 3, 8} {
+		q.Enqueue(v)
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(q); err != nil {
+		t.Fatal(err)
+	}
+	r, _ := NewSyncPriorityQueue(0, less)
+	if err := gob.NewDecoder(&buf).Decode(r); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := r.Dequeue(); v != 3 || r.Len() != 2 {
+		t.Errorf("Dequeue = %d, Len = %d, want 3, 2", v, r.Len())
+	}
+}
+
+func TestAgingFuncValidation(t *testing.T) {
+	mustPanic := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s did not panic", name)
+			}
+		}()
+		fn()
+	}
+	mustPanic("StepAging(0)", func() { StepAging(0, 1) })
+	mustPanic("StepAging(-1s)", func() { StepAging(-time.Second, 1) })
+	mustPanic("ExponentialAging(0)", func() { ExponentialAging(0) })
+
+	if got := StepAging(time.Second, 2)(1, 3500*time.Millisecond); got != 7 {
+		t.Errorf("StepAging = %v, want 7", got)
+	}
+	if got := ExponentialAging(time.Second)(3, 2*time.Second); got != 12 {
+		t.Errorf("ExponentialAging = %v, want 12", got)
+	}
+}
+
+func TestSyncPriorityQueueRestoreIsAtomic(t *testing.T) {
+	const n = 2000
+	items := make([]int, n)
+	for i := range items {
+		items[i] = n - i
+	}
+	b, err := json.Marshal(snapshot[int]{Items: items})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q, _ := NewSyncPriorityQueue(0, less)
+	got := make(chan int, 1)
+	go func() {
+		v, _ := q.DequeueContext(context.Background())
+		got <- v
+	}()
+
+	// 并发观察 Len：只能看到恢复前（0）或恢复后（n，或等待者取走一个后的 n-1）
+	stop := make(chan struct{})
+	bad := make(chan int, 1)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				close(bad)
+				return
+			default:
+			}
+			if l := q.Len(); l != 0 && l != n && l != n-1 {
+				bad <- l
+				return
+			}
+		}
+	}()
+
+	if err := q.UnmarshalJSON(b); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case v := <-got:
+		if v != 1 {
+			t.Errorf("waiter got %d, want 1", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("DequeueContext waiter was not woken by restore")
+	}
+	close(stop)
+	if l, ok := <-bad; ok {
+		t.Fatalf("observed partially restored queue with Len %d", l)
+	}
+}
+
+// TestSyncPriorityQueueMarshalConsistent 与 restore 并发编码时，容量与元素来自同一个状态
+func TestSyncPriorityQueueMarshalConsistent(t *testing.T) {
+	small, _ := json.Marshal(snapshot[int]{Capacity: 3, Items: []int{1, 2, 3}})
+	large, _ := json.Marshal(snapshot[int]{Capacity: 0, Items: []int{10, 11, 12, 13, 14, 15, 16, 17}})
+	q, _ := NewSyncPriorityQueue(0, less)
+	q.UnmarshalJSON(small)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 500; i++ {
+			if i%2 == 0 {
+				q.UnmarshalJSON(large)
+			} else {
+				q.UnmarshalJSON(small)
+			}
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		b, _ := q.MarshalJSON()
+		var s snapshot[int]
+		if err := json.Unmarshal(b, &s); err != nil {
+			t.Fatal(err)
+		}
+		if (s.Capacity == 3) != (len(s.Items) == 3) {
+			t.Fatalf("capacity %d paired with %d items", s.Capacity, len(s.Items))
+		}
+		if _, err := q.GobEncode(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
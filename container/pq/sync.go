@@ -0,0 +1,172 @@
+package pq
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+)
+
+// SyncPriorityQueue 是并发安全、基于堆的优先队列，支持泛型
+// 内部使用最小-最大堆，入队、出队以及有界模式下丢弃最差元素均为 O(logN)
+type SyncPriorityQueue[T any] struct {
+	mu       sync.Mutex
+	heap     *minMaxHeap[T]
+	capacity int
+	better   func(a, b T) bool // 比较函数，返回true表示a应该排在b前面
+	ready    chan struct{}     // 有等待者时非nil，入队时关闭以唤醒等待者
+}
+
+// NewSyncPriorityQueue 创建一个新的并发安全优先队列
+// capacity为0表示没有容量限制
+// 时间复杂度: O(1)
+func NewSyncPriorityQueue[T any](capacity int, better func(a, b T) bool) (*SyncPriorityQueue[T], error) {
+	if capacity < 0 {
+		return nil, errors.New("capacity must be non-negative")
+	}
+	if better == nil {
+		return nil, errors.New("better function cannot be nil")
+	}
+	return &SyncPriorityQueue[T]{
+		heap:     newMinMaxHeap(capacity, better),
+		capacity: capacity,
+		better:   better,
+	}, nil
+}
+
+// Len 返回队列当前长度
+// 时间复杂度: O(1)
+func (pq *SyncPriorityQueue[T]) Len() int {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return pq.heap.len()
+}
+
+// Enqueue 添加元素到队列中
+// 如果超出capacity，则丢弃优先级最低的元素（可能是新元素本身）
+// 时间复杂度: O(logN)
+func (pq *SyncPriorityQueue[T]) Enqueue(item T) error {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	if pq.capacity > 0 && pq.heap.len() == pq.capacity {
+		if !pq.better(item, pq.heap.data[pq.heap.maxIndex()]) {
+			return nil
+		}
+		pq.heap.popMax()
+	}
+	pq.heap.push(item)
+
+	// 唤醒阻塞在 DequeueContext 上的等待者
+	if pq.ready != nil {
+		close(pq.ready)
+		pq.ready = nil
+	}
+	return nil
+}
+
+// Dequeue 移除并返回队列头部元素（优先级最高的元素）
+// 时间复杂度: O(logN)
+func (pq *SyncPriorityQueue[T]) Dequeue() (T, error) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	if pq.heap.len() == 0 {
+		var zero T
+		return zero, errors.New("queue is empty")
+	}
+	return pq.heap.popMin(), nil
+}
+
+// DequeueContext 移除并返回队列头部元素；队列为空时阻塞，直到有元素入队或ctx结束
+// 时间复杂度: O(logN)
+func (pq *SyncPriorityQueue[T]) DequeueContext(ctx context.Context) (T, error) {
+	for {
+		pq.mu.Lock()
+		if pq.heap.len() > 0 {
+			item := pq.heap.popMin()
+			pq.mu.Unlock()
+			return item, nil
+		}
+		if pq.ready == nil {
+			pq.ready = make(chan struct{})
+		}
+		ready := pq.ready
+		pq.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		case <-ready:
+		}
+	}
+}
+
+// Peek 返回队列头部元素但不移除
+// 时间复杂度: O(1)
+func (pq *SyncPriorityQueue[T]) Peek() (T, error) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	if pq.heap.len() == 0 {
+		var zero T
+		return zero, errors.New("queue is empty")
+	}
+	return pq.heap.data[0], nil
+}
+
+// Snapshot 返回按优先级从高到低排列的元素副本
+// 时间复杂度: O(NlogN)
+func (pq *SyncPriorityQueue[T]) Snapshot() []T {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return sortedBy(pq.heap.data, pq.better)
+}
+
+// All 返回按优先级从高到低遍历的迭代器，不修改队列
+// 遍历的是开始迭代时的快照，不会阻塞其他 goroutine
+func (pq *SyncPriorityQueue[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, item := range pq.Snapshot() {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Drain 返回边遍历边出队的迭代器，队列为空时结束
+// 每次出队单独加锁，遍历期间其他 goroutine 仍可入队
+func (pq *SyncPriorityQueue[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			item, err := pq.Dequeue()
+			if err != nil || !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Clear 清空队列
+// 时间复杂度: O(N)
+func (pq *SyncPriorityQueue[T]) Clear() {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	pq.heap.clear()
+}
+
+// Clone 返回队列的副本，与原队列共享 better 函数
+// 时间复杂度: O(N)
+func (pq *SyncPriorityQueue[T]) Clone() *SyncPriorityQueue[T] {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	h := newMinMaxHeap(max(pq.capacity, pq.heap.len()), pq.better)
+	h.data = append(h.data, pq.heap.data...)
+	return &SyncPriorityQueue[T]{
+		heap:     h,
+		capacity: pq.capacity,
+		better:   pq.better,
+	}
+}
@@ -0,0 +1,98 @@
+// Package reorder 提供带有界重排缓冲区的有序并发映射，供 container/list 和 parallel/pipeline 共用
+package reorder
+
+import (
+	"context"
+	"sync"
+)
+
+// seqItem 带序号的数据，用于重排
+type seqItem[X any] struct {
+	seq int
+	v   X
+}
+
+// Map 用 workers 个 goroutine 并发对 in 中的数据执行 f，按输入顺序把结果发送到 out
+// 派发时给数据编号，结果经重排缓冲区按编号顺序发出；每条在途数据占用一个令牌，发出后归还，
+// 保证已派发但尚未发出的数据不超过 window 条（window 应不小于 workers）
+// in 关闭且所有结果发出后，或 ctx 结束后，关闭 out
+func Map[X, Y any](ctx context.Context, workers, window int, f func(X) Y, in <-chan X, out chan<- Y) {
+	tokens := make(chan struct{}, window)
+	jobs := make(chan seqItem[X])
+	results := make(chan seqItem[Y], workers)
+
+	// 派发：先取令牌再读取输入，避免读出的数据因取不到令牌而滞留
+	go func() {
+		defer close(jobs)
+		for seq := 0; ; seq++ {
+			select {
+			case <-ctx.Done():
+				return
+			case tokens <- struct{}{}:
+			}
+			var x X
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				x = v
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- seqItem[X]{seq: seq, v: x}:
+			}
+		}
+	}()
+
+	// worker 池
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				y := f(job.v)
+				select {
+				case <-ctx.Done():
+					return
+				case results <- seqItem[Y]{seq: job.seq, v: y}:
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// 重排：缓存乱序到达的结果，按编号顺序发出
+	go func() {
+		defer close(out)
+		pending := make(map[int]Y, window)
+		next := 0
+		for r := range results {
+			pending[r.seq] = r.v
+			for {
+				y, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				select {
+				case <-ctx.Done():
+					// 排空 results，让 worker 能够退出
+					for range results {
+					}
+					return
+				case out <- y:
+				}
+				<-tokens
+				next++
+			}
+		}
+	}()
+}
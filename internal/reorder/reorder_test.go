@@ -0,0 +1,49 @@
+package reorder
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMapKeepsOrder(t *testing.T) {
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := 0; i < 100; i++ {
+			in <- i
+		}
+	}()
+	out := make(chan int)
+	// 小的数据处理得慢，迫使结果乱序到达
+	Map(context.Background(), 4, 8, func(x int) int {
+		time.Sleep(time.Duration(100-x) * 10 * time.Microsecond)
+		return x * 2
+	}, in, out)
+	want := 0
+	for y := range out {
+		if y != want*2 {
+			t.Fatalf("got %d, want %d", y, want*2)
+		}
+		want++
+	}
+	if want != 100 {
+		t.Fatalf("got %d results, want 100", want)
+	}
+}
+
+func TestMapCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int) // 永不关闭
+	out := make(chan int)
+	Map(ctx, 2, 2, func(x int) int { return x }, in, out)
+	cancel()
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("unexpected result")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("out not closed after cancel")
+	}
+}
@@ -1,32 +1,155 @@
 package pipeline
 
-import "sync"
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/leoxiang66/go-patterns/container/deque"
+)
+
+// SlowPolicy 订阅者跟不上广播速度（缓冲区已满）时的处理策略
+type SlowPolicy int
+
+const (
+	Block      SlowPolicy = iota // 阻塞等待该订阅者（默认），会拖慢所有订阅者
+	DropNewest                   // 丢弃当前消息
+	DropOldest                   // 丢弃缓冲区中最旧的消息，腾出位置给当前消息（缓冲区至少为1）
+	Disconnect                   // 断开该订阅者：关闭其通道并取消订阅
+)
+
+// SubscribeOptions 订阅配置
+type SubscribeOptions struct {
+	Buffer int        // 订阅通道的缓冲区大小
+	Policy SlowPolicy // 缓冲区已满时的策略
+	Replay int        // 订阅时先收到最近的若干条历史消息（不超过 KeepHistory 设置的条数）
+}
+
+// SubscriberStats 订阅者计数
+type SubscriberStats struct {
+	Delivered    uint64 // 已放入订阅通道的消息数（包括重放的历史消息，以及之后被 DropOldest 挤掉的消息）
+	Dropped      uint64 // 因 DropNewest/DropOldest 丢弃的消息数
+	Disconnected bool   // 是否因 Disconnect 策略被断开
+}
+
+// Subscription 一个订阅：C 接收消息，Done 在取消订阅、被断开或广播结束时关闭
+type Subscription[X any] struct {
+	b            *Broadcast[X]
+	ch           chan X
+	policy       SlowPolicy
+	done         chan struct{}
+	doneOnce     sync.Once
+	closed       bool // ch 是否已关闭，由 b.mu 保护
+	delivered    atomic.Uint64
+	dropped      atomic.Uint64
+	disconnected atomic.Bool
+}
+
+// C 返回接收广播消息的通道，订阅结束后关闭
+func (s *Subscription[X]) C() <-chan X { return s.ch }
+
+// Done 返回订阅结束时关闭的通道
+func (s *Subscription[X]) Done() <-chan struct{} { return s.done }
+
+// Stats 返回订阅者计数
+func (s *Subscription[X]) Stats() SubscriberStats {
+	return SubscriberStats{
+		Delivered:    s.delivered.Load(),
+		Dropped:      s.dropped.Load(),
+		Disconnected: s.disconnected.Load(),
+	}
+}
+
+// Unsubscribe 取消订阅并关闭 C，可重复调用；正阻塞在该订阅者上的发送会立即放弃
+func (s *Subscription[X]) Unsubscribe() {
+	s.finish()
+	s.b.mu.Lock()
+	s.b.remove(s)
+	s.b.mu.Unlock()
+}
+
+func (s *Subscription[X]) finish() {
+	s.doneOnce.Do(func() { close(s.done) })
+}
 
 // Broadcast 广播组件：将输入通道in的消息广播到所有订阅的输出通道
 type Broadcast[X any] struct {
-	mu          sync.RWMutex    // 保护订阅者列表
-	subscribers []chan X        // 订阅者通道列表
-	in          <-chan X        // 输入通道
-	quit        <-chan struct{} // 终止信号
+	mu          sync.RWMutex       // 保护订阅者列表和历史消息
+	subscribers []*Subscription[X] // 订阅者列表
+	history     *deque.Deque[X]    // 最近的历史消息（满后覆盖最旧的），供新订阅者重放；不保留时为nil
+	finished    bool               // Run 是否已结束
+	in          <-chan X           // 输入通道
+	quit        <-chan struct{}    // 终止信号
 }
 
 // NewBroadcast 初始化广播组件
 func NewBroadcast[X any](q <-chan struct{}, in <-chan X) *Broadcast[X] {
 	b := &Broadcast[X]{
-		subscribers: make([]chan X, 0),
+		subscribers: make([]*Subscription[X], 0),
 		in:          in,
 		quit:        q,
 	}
 	return b
 }
 
-// Subscribe 订阅广播（返回一个接收广播消息的通道）
+// KeepHistory 保留最近 n 条消息，供设置了 Replay 的订阅者重放
+func (b *Broadcast[X]) KeepHistory(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	old := b.history
+	b.history = nil
+	if n <= 0 {
+		return
+	}
+	b.history, _ = deque.NewBounded[X](n, true)
+	if old != nil {
+		for i := max(0, old.Len()-n); i < old.Len(); i++ {
+			x, _ := old.At(i)
+			b.history.PushBack(x)
+		}
+	}
+}
+
+// Subscribe 订阅广播（返回一个接收广播消息的无缓冲通道，采用 Block 策略）
 func (b *Broadcast[X]) Subscribe() <-chan X {
-	ch := make(chan X)
+	return b.SubscribeWith(SubscribeOptions{}).C()
+}
+
+// SubscribeWith 按 opts 订阅广播；广播已结束时返回的订阅会立即结束
+// 重放的历史消息预先放入通道，不占用 Buffer
+func (b *Broadcast[X]) SubscribeWith(opts SubscribeOptions) *Subscription[X] {
+	buffer := max(0, opts.Buffer)
+	if opts.Policy == DropOldest {
+		buffer = max(1, buffer)
+	}
+
 	b.mu.Lock()
-	b.subscribers = append(b.subscribers, ch)
-	b.mu.Unlock()
-	return ch
+	defer b.mu.Unlock()
+	var replay []X
+	if b.history != nil {
+		n := b.history.Len()
+		for i := n - min(max(0, opts.Replay), n); i < n; i++ {
+			x, _ := b.history.At(i)
+			replay = append(replay, x)
+		}
+	}
+	s := &Subscription[X]{
+		b:      b,
+		ch:     make(chan X, buffer+len(replay)),
+		policy: opts.Policy,
+		done:   make(chan struct{}),
+	}
+	for _, x := range replay {
+		s.ch <- x
+	}
+	s.delivered.Add(uint64(len(replay)))
+	if b.finished {
+		s.finish()
+		close(s.ch)
+		s.closed = true
+		return s
+	}
+	b.subscribers = append(b.subscribers, s)
+	return s
 }
 
 // Run 核心广播逻辑：从输入通道读消息，发送到所有订阅者
@@ -34,34 +157,114 @@ func (b *Broadcast[X]) Run() {
 	for {
 		select {
 		case <-b.quit: // 响应终止信号，关闭所有订阅者通道
-			b.mu.Lock()
-			for _, ch := range b.subscribers {
-				close(ch)
-			}
-			b.subscribers = nil
-			b.mu.Unlock()
+			b.closeAll()
 			return
 		case x, ok := <-b.in: // 读取输入消息
 			if !ok { // 输入通道关闭，退出广播
-				b.mu.Lock()
-				for _, ch := range b.subscribers {
-					close(ch)
-				}
-				b.subscribers = nil
-				b.mu.Unlock()
+				b.closeAll()
 				return
 			}
+			b.record(x)
 			// 广播消息到所有订阅者
-			b.mu.RLock()
-			for _, ch := range b.subscribers {
+			if !b.send(x) {
+				b.closeAll()
+				return
+			}
+		}
+	}
+}
+
+// record 把消息加入历史，历史已满时覆盖最旧的一条，O(1)
+func (b *Broadcast[X]) record(x X) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.history != nil {
+		b.history.PushBack(x)
+	}
+}
+
+// send 按各订阅者的策略发送消息，收到终止信号时返回false
+func (b *Broadcast[X]) send(x X) bool {
+	var slow []*Subscription[X] // 需要断开的订阅者
+	b.mu.RLock()
+	for _, s := range b.subscribers {
+		switch s.policy {
+		case DropNewest:
+			select {
+			case s.ch <- x:
+				s.delivered.Add(1)
+			default:
+				s.dropped.Add(1)
+			}
+		case DropOldest:
+			for sent := false; !sent; {
 				select {
-				case <-b.quit:
-					b.mu.RUnlock()
-					return
-				case ch <- x: // 发送消息到订阅者
+				case s.ch <- x:
+					s.delivered.Add(1)
+					sent = true
+				default:
+					select {
+					case <-s.ch: // 丢弃最旧的一条；若恰好被订阅者读走则直接重试
+						s.dropped.Add(1)
+					default:
+					}
 				}
 			}
-			b.mu.RUnlock()
+		case Disconnect:
+			select {
+			case s.ch <- x:
+				s.delivered.Add(1)
+			default:
+				s.disconnected.Store(true)
+				s.finish()
+				slow = append(slow, s)
+			}
+		default:
+			select {
+			case <-b.quit:
+				b.mu.RUnlock()
+				return false
+			case <-s.done: // 已取消订阅
+			case s.ch <- x: // 发送消息到订阅者
+				s.delivered.Add(1)
+			}
+		}
+	}
+	b.mu.RUnlock()
+
+	if len(slow) > 0 {
+		b.mu.Lock()
+		for _, s := range slow {
+			b.remove(s)
 		}
+		b.mu.Unlock()
 	}
+	return true
+}
+
+// remove 从订阅者列表中移除 s 并关闭其通道，调用方需持有写锁
+func (b *Broadcast[X]) remove(s *Subscription[X]) {
+	for i, x := range b.subscribers {
+		if x == s {
+			b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+			break
+		}
+	}
+	if !s.closed {
+		close(s.ch)
+		s.closed = true
+	}
+}
+
+// closeAll 关闭并清空所有订阅者通道
+func (b *Broadcast[X]) closeAll() {
+	b.mu.Lock()
+	for _, s := range b.subscribers {
+		s.finish()
+		close(s.ch)
+		s.closed = true
+	}
+	b.subscribers = nil
+	b.finished = true
+	b.mu.Unlock()
 }
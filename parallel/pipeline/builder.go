@@ -0,0 +1,386 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Builder 声明式流水线构建器：用 AddSource/AddMap/AddFanOut/AddMerge/AddSink 声明有向无环图，
+// Build 校验后得到可运行的 Graph。节点只能引用已创建的节点，因此图天然无环
+type Builder struct {
+	stages []*stage
+	names  map[string]bool
+	buffer int
+	err    error // 声明阶段遇到的第一个错误，Build 时返回
+	built  bool  // 已成功 Build：阶段与通道归返回的 Graph 所有，不能再生成第二个
+}
+
+// Node 类型化的节点句柄，表示某个阶段的一个输出端口（元素类型为 T）
+type Node[T any] struct {
+	s    *stage
+	port int
+}
+
+// stage 图中的一个阶段；阶段之间用 chan any 连接，类型安全由 Node[T] 在声明时保证
+type stage struct {
+	name   string
+	source bool
+	inputs []chan any // 输入通道（即上游的输出端口）
+	outs   []chan any // 输出端口，sink 为空
+	used   []int      // 每个输出端口的下游数量
+	run    func(ctx context.Context, st *stage) error
+
+	in, out atomic.Int64
+	busy    atomic.Int64 // 处理函数累计耗时（纳秒）
+}
+
+// StageMetrics 阶段指标快照
+type StageMetrics struct {
+	Name     string
+	In       int64         // 已接收的数据条数
+	Out      int64         // 已发出的数据条数
+	QueueLen int           // 输入通道中排队的数据条数
+	Busy     time.Duration // 处理函数累计耗时（多个 worker 时为总和）
+}
+
+// NewBuilder 创建构建器，buffer 为阶段之间通道的缓冲区大小
+func NewBuilder(buffer int) *Builder {
+	return &Builder{names: make(map[string]bool), buffer: max(0, buffer)}
+}
+
+func (b *Builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// add 登记一个有 outs 个输出端口的阶段，name 为空时自动命名
+func (b *Builder) add(name string, outs int) *stage {
+	if name == "" {
+		name = fmt.Sprintf("stage-%d", len(b.stages))
+	}
+	if b.names[name] {
+		b.fail(fmt.Errorf("pipeline: duplicate stage name %q", name))
+	}
+	b.names[name] = true
+	st := &stage{name: name, outs: make([]chan any, outs), used: make([]int, outs)}
+	for i := range st.outs {
+		st.outs[i] = make(chan any, b.buffer)
+	}
+	b.stages = append(b.stages, st)
+	return st
+}
+
+// connect 把节点 n 接为 st 的输入
+func connect[T any](b *Builder, st *stage, n Node[T]) {
+	if n.s == nil {
+		b.fail(fmt.Errorf("pipeline: stage %q has a zero Node as input", st.name))
+		return
+	}
+	if !b.owns(n.s) {
+		b.fail(fmt.Errorf("pipeline: stage %q uses a node from another builder", st.name))
+		return
+	}
+	n.s.used[n.port]++
+	st.inputs = append(st.inputs, n.s.outs[n.port])
+}
+
+func (b *Builder) owns(s *stage) bool {
+	for _, x := range b.stages {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// send 在 ctx 结束前把 v 发送到 ch，并计数
+func (st *stage) send(ctx context.Context, ch chan<- any, v any) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case ch <- v:
+		st.out.Add(1)
+		return true
+	}
+}
+
+// recv 从 ch 读取一条数据，ch 关闭或 ctx 结束时返回 false
+func (st *stage) recv(ctx context.Context, ch <-chan any) (any, bool) {
+	select {
+	case <-ctx.Done():
+		return nil, false
+	case v, ok := <-ch:
+		if ok {
+			st.in.Add(1)
+		}
+		return v, ok
+	}
+}
+
+// timed 调用 fn 并累计耗时
+func (st *stage) timed(fn func()) {
+	start := time.Now()
+	fn()
+	st.busy.Add(int64(time.Since(start)))
+}
+
+// AddSource 声明数据源：gen 通过 emit 发送数据，emit 返回 false 时（Stop 或流水线终止）应尽快返回
+// gen 返回后源的输出通道关闭，下游处理完剩余数据后依次退出
+func AddSource[T any](b *Builder, name string, gen func(ctx context.Context, emit func(T) bool) error) Node[T] {
+	st := b.add(name, 1)
+	st.source = true
+	st.run = func(ctx context.Context, st *stage) error {
+		return gen(ctx, func(v T) bool { return st.send(ctx, st.outs[0], v) })
+	}
+	return Node[T]{s: st}
+}
+
+// AddMap 声明转换阶段：workers 个 goroutine 并发执行 f（输出无序），workers 小于1时按1处理
+// f 返回错误或 panic 时包装为 StageError 并终止整条流水线
+func AddMap[X, Y any](b *Builder, name string, from Node[X], workers int, f func(ctx context.Context, x X) (Y, error)) Node[Y] {
+	st := b.add(name, 1)
+	connect(b, st, from)
+	workers = max(1, workers)
+	st.run = func(ctx context.Context, st *stage) error {
+		var (
+			wg      sync.WaitGroup
+			errOnce sync.Once
+			first   error
+		)
+		ctx, cancel := context.WithCancel(ctx) // 出错时让其余 worker 立即退出
+		defer cancel()
+		wg.Add(workers)
+		for w := 0; w < workers; w++ {
+			go func() {
+				defer wg.Done()
+				for {
+					v, ok := st.recv(ctx, st.inputs[0])
+					if !ok {
+						return
+					}
+					var (
+						y   Y
+						err error
+					)
+					st.timed(func() { y, err = safeApply(ctx, f, v.(X)) })
+					if err != nil {
+						errOnce.Do(func() {
+							first = &StageError{Stage: st.name, Item: v, Attempts: 1, Err: err}
+							cancel()
+						})
+						return
+					}
+					if !st.send(ctx, st.outs[0], y) {
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		return first
+	}
+	return Node[Y]{s: st}
+}
+
+// AddFanOut 声明分发阶段：把 from 的数据分发到 n 个输出（每条数据只进入其中一个，由空闲的下游竞争获取）
+func AddFanOut[T any](b *Builder, name string, from Node[T], n int) []Node[T] {
+	if n <= 0 {
+		b.fail(fmt.Errorf("pipeline: fan-out %q needs at least one output", name))
+		n = 1
+	}
+	st := b.add(name, n)
+	connect(b, st, from)
+	st.run = func(ctx context.Context, st *stage) error {
+		var wg sync.WaitGroup
+		wg.Add(len(st.outs))
+		for _, out := range st.outs {
+			go func(out chan any) {
+				defer wg.Done()
+				for {
+					v, ok := st.recv(ctx, st.inputs[0])
+					if !ok || !st.send(ctx, out, v) {
+						return
+					}
+				}
+			}(out)
+		}
+		wg.Wait()
+		return nil
+	}
+	nodes := make([]Node[T], n)
+	for i := range nodes {
+		nodes[i] = Node[T]{s: st, port: i}
+	}
+	return nodes
+}
+
+// AddMerge 声明合并阶段：把多个输入合并为一个输出，所有输入关闭后输出关闭
+func AddMerge[T any](b *Builder, name string, from ...Node[T]) Node[T] {
+	st := b.add(name, 1)
+	if len(from) == 0 {
+		b.fail(fmt.Errorf("pipeline: merge %q needs at least one input", st.name))
+	}
+	for _, n := range from {
+		connect(b, st, n)
+	}
+	st.run = func(ctx context.Context, st *stage) error {
+		var wg sync.WaitGroup
+		wg.Add(len(st.inputs))
+		for _, in := range st.inputs {
+			go func(in chan any) {
+				defer wg.Done()
+				for {
+					v, ok := st.recv(ctx, in)
+					if !ok || !st.send(ctx, st.outs[0], v) {
+						return
+					}
+				}
+			}(in)
+		}
+		wg.Wait()
+		return nil
+	}
+	return Node[T]{s: st}
+}
+
+// AddSink 声明终点阶段：对每条数据调用 f，f 返回错误或 panic 时终止整条流水线
+func AddSink[T any](b *Builder, name string, from Node[T], f func(ctx context.Context, x T) error) {
+	st := b.add(name, 0)
+	connect(b, st, from)
+	sink := func(ctx context.Context, x T) (struct{}, error) { return struct{}{}, f(ctx, x) }
+	st.run = func(ctx context.Context, st *stage) error {
+		for {
+			v, ok := st.recv(ctx, st.inputs[0])
+			if !ok {
+				return nil
+			}
+			var err error
+			st.timed(func() { _, err = safeApply(ctx, sink, v.(T)) })
+			if err != nil {
+				return &StageError{Stage: st.name, Item: v, Attempts: 1, Err: err}
+			}
+		}
+	}
+}
+
+// Build 校验图并返回可运行的 Graph：至少有一个源和一个终点，每个输出端口恰好被一个下游消费
+// 各阶段的通道在声明时创建，因此只能成功 Build 一次，再次调用返回错误
+func (b *Builder) Build() (*Graph, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.built {
+		return nil, errors.New("pipeline: builder already built")
+	}
+	var sources, sinks int
+	for _, st := range b.stages {
+		if st.source {
+			sources++
+		}
+		if len(st.outs) == 0 {
+			sinks++
+		}
+		for port, n := range st.used {
+			switch {
+			case n == 0:
+				return nil, fmt.Errorf("pipeline: output %d of stage %q is not consumed", port, st.name)
+			case n > 1:
+				return nil, fmt.Errorf("pipeline: output %d of stage %q is consumed by %d stages; use AddFanOut", port, st.name, n)
+			}
+		}
+	}
+	if sources == 0 {
+		return nil, errors.New("pipeline: graph has no source")
+	}
+	if sinks == 0 {
+		return nil, errors.New("pipeline: graph has no sink")
+	}
+	b.built = true
+	return &Graph{stages: b.stages}, nil
+}
+
+// Graph 已校验的流水线图，只能启动一次
+type Graph struct {
+	stages []*stage
+	mu     sync.Mutex // 保护 p 与 stop，使 Stop/Wait 可以与 Start 并发调用
+	p      *Pipeline
+	stop   context.CancelFunc // 只取消数据源
+}
+
+// Start 启动所有阶段；parent 结束或任一阶段出错时整条流水线立即终止
+func (g *Graph) Start(parent context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.p != nil {
+		return errors.New("pipeline: graph already started")
+	}
+	g.p = NewPipeline(parent)
+	srcCtx, stop := context.WithCancel(g.p.Context())
+	g.stop = stop
+	for _, st := range g.stages {
+		ctx := g.p.Context()
+		if st.source {
+			ctx = srcCtx
+		}
+		g.p.Go(func(context.Context) error {
+			defer func() {
+				for _, ch := range st.outs {
+					close(ch)
+				}
+			}()
+			err := st.run(ctx, st)
+			if st.source && srcCtx.Err() != nil && g.p.Context().Err() == nil && errors.Is(err, context.Canceled) {
+				err = nil // 由 Stop 引起的取消不算错误
+			}
+			return err
+		})
+	}
+	return nil
+}
+
+// Stop 优雅停止：只取消数据源，已进入流水线的数据会被处理完；需配合 Wait 等待结束
+func (g *Graph) Stop() {
+	g.mu.Lock()
+	stop := g.stop
+	g.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+}
+
+// Wait 等待所有阶段退出，返回导致终止的第一个错误（阶段错误为 *StageError）
+func (g *Graph) Wait() error {
+	g.mu.Lock()
+	p, stop := g.p, g.stop
+	g.mu.Unlock()
+	if p == nil {
+		return errors.New("pipeline: graph not started")
+	}
+	err := p.Wait()
+	stop()
+	return err
+}
+
+// Metrics 返回各阶段的指标快照，顺序与声明顺序一致；运行中也可调用
+func (g *Graph) Metrics() []StageMetrics {
+	ms := make([]StageMetrics, len(g.stages))
+	for i, st := range g.stages {
+		q := 0
+		for _, in := range st.inputs {
+			q += len(in)
+		}
+		ms[i] = StageMetrics{
+			Name:     st.name,
+			In:       st.in.Load(),
+			Out:      st.out.Load(),
+			QueueLen: q,
+			Busy:     time.Duration(st.busy.Load()),
+		}
+	}
+	return ms
+}
@@ -0,0 +1,373 @@
+package pipeline
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+
+	"github.com/leoxiang66/go-patterns/parallel/barrier"
+)
+
+// 可恢复的流水线：数据源 → 一个或多个有状态阶段 → 事务性文件终点。
+// 数据源每隔若干条数据插入一个 barrier 标记，标记随数据流经各阶段（类似 Chandy–Lamport 快照）：
+// 数据源记录偏移量，每个阶段保存各自的状态快照，终点刷盘并记录文件长度，所有参与者都确认后写入检查点。
+// 崩溃后从最后一个检查点恢复：数据源从记录的偏移量重放，各阶段恢复状态，终点文件截断到已提交的长度，
+// 因此输出文件中的每条结果恰好出现一次。
+
+// Replayable 可重放的数据源：从第 offset 条数据（从0开始）开始产生数据
+type Replayable[X any] func(offset int64) iter.Seq[X]
+
+// StatefulStage 有状态的处理阶段
+type StatefulStage[X, Y any] interface {
+	Process(x X) ([]Y, error)  // 处理一条数据，可产生任意条结果
+	Snapshot() ([]byte, error) // 导出当前状态
+	Restore(state []byte) error
+}
+
+// Checkpoint 检查点：恢复所需的全部信息
+type Checkpoint struct {
+	Epoch   int64    `json:"epoch"`    // 检查点序号，从1开始
+	Offset  int64    `json:"offset"`   // 数据源已处理的条数
+	States  [][]byte `json:"states"`   // 各阶段的状态快照，顺序与阶段顺序一致
+	SinkLen int64    `json:"sink_len"` // 终点文件已提交的长度
+}
+
+// FileCheckpointStore 把检查点保存在本地文件中，写入是原子的（临时文件 + fsync + rename）
+type FileCheckpointStore struct {
+	path string
+}
+
+// NewFileCheckpointStore 创建以 path 为检查点文件的存储
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{path: path}
+}
+
+// Load 读取最后一个检查点，不存在时返回 nil, nil
+func (s *FileCheckpointStore) Load() (*Checkpoint, error) {
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(b, cp); err != nil {
+		return nil, fmt.Errorf("pipeline: corrupt checkpoint %s: %w", s.path, err)
+	}
+	return cp, nil
+}
+
+// Save 原子地替换检查点文件：崩溃时文件要么是旧检查点，要么是新检查点
+func (s *FileCheckpointStore) Save(cp *Checkpoint) error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(s.path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // rename 成功后为空操作
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+	// 同步目录，确保 rename 持久化
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// FileSink 事务性文件终点：写入先进入缓冲，Commit 时刷盘并返回已提交的长度
+type FileSink struct {
+	f *os.File
+	w *bufio.Writer
+	n int64 // 已写入的长度（含未提交部分）
+}
+
+// OpenFileSink 打开 path 并截断到 committed 长度，丢弃上次崩溃前未提交的内容
+func OpenFileSink(path string, committed int64) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(committed); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(committed, 0); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &FileSink{f: f, w: bufio.NewWriter(f), n: committed}, nil
+}
+
+// Write 写入一条结果（未提交）
+func (s *FileSink) Write(b []byte) error {
+	n, err := s.w.Write(b)
+	s.n += int64(n)
+	return err
+}
+
+// Commit 刷新缓冲并 fsync，返回已提交的长度
+func (s *FileSink) Commit() (int64, error) {
+	if err := s.w.Flush(); err != nil {
+		return 0, err
+	}
+	if err := s.f.Sync(); err != nil {
+		return 0, err
+	}
+	return s.n, nil
+}
+
+// Close 关闭文件，未提交的内容可能已写入文件，恢复时会被截断
+func (s *FileSink) Close() error {
+	s.w.Flush()
+	return s.f.Close()
+}
+
+// CheckpointConfig 可恢复流水线的配置
+type CheckpointConfig struct {
+	Interval int                  // 每处理多少条源数据插入一个 barrier，小于1时按1处理
+	Store    *FileCheckpointStore // 检查点存储
+	SinkPath string               // 输出文件路径
+}
+
+// CheckpointStage 类型擦除后的有状态阶段，用于把输入输出类型不同的多个阶段串联起来
+type CheckpointStage = StatefulStage[any, any]
+
+// erasedStage EraseStage 的实现
+type erasedStage[X, Y any] struct {
+	StatefulStage[X, Y]
+}
+
+func (s erasedStage[X, Y]) Process(x any) ([]any, error) {
+	ys, err := s.StatefulStage.Process(x.(X))
+	out := make([]any, len(ys))
+	for i, y := range ys {
+		out[i] = y
+	}
+	return out, err
+}
+
+// EraseStage 擦除阶段的类型参数，供 RunCheckpointedChain 串联；相邻阶段的输出与输入类型须一致，否则处理时 panic
+func EraseStage[X, Y any](s StatefulStage[X, Y]) CheckpointStage {
+	return erasedStage[X, Y]{s}
+}
+
+// epoch 一个检查点周期：数据源、每个阶段和终点各自记录自己的部分并确认
+// 标记按顺序流经各参与者，终点总是最后一个确认，由它等待屏障并关闭 synced
+type epoch struct {
+	cp     Checkpoint
+	done   *barrier.EasyBarrier // 大小为参与者个数（阶段数 + 2）
+	synced chan struct{}        // 所有参与者确认后关闭
+}
+
+// envelope 流经各阶段的数据或 barrier 标记
+type envelope[T any] struct {
+	v       T
+	barrier *epoch // 非nil时为 barrier 标记
+}
+
+// RunCheckpointed 运行只有一个阶段的可恢复流水线，见 RunCheckpointedChain
+// 参数：
+//
+//	ctx: 控制终止，取消等价于崩溃（最后一个检查点之后的输出会在恢复时丢弃并重新生成）
+//	cfg: 检查点间隔、存储与输出文件
+//	src: 可重放的数据源
+//	stage: 有状态的处理阶段
+//	encode: 把结果编码为写入文件的字节（需自带分隔符）
+func RunCheckpointed[X, Y any](
+	ctx context.Context,
+	cfg CheckpointConfig,
+	src Replayable[X],
+	stage StatefulStage[X, Y],
+	encode func(y Y) []byte,
+) error {
+	return RunCheckpointedChain(ctx, cfg, src, []CheckpointStage{EraseStage(stage)}, func(y any) []byte {
+		return encode(y.(Y))
+	})
+}
+
+// RunCheckpointedChain 运行由多个有状态阶段串联而成的可恢复流水线，直到数据源耗尽（此时写入最终检查点）、出错或 ctx 结束
+// 每个阶段运行在独立的 goroutine 中；存在检查点时从检查点恢复，重新运行已完成的流水线不会产生新的输出
+// 中途终止时尚未被所有参与者确认的周期直接放弃，不会写入检查点
+// 参数：
+//
+//	ctx: 控制终止，取消等价于崩溃
+//	cfg: 检查点间隔、存储与输出文件
+//	src: 可重放的数据源
+//	stages: 按顺序串联的阶段（用 EraseStage 转换），至少一个
+//	encode: 把最后一个阶段的结果编码为写入文件的字节（需自带分隔符）
+func RunCheckpointedChain[X any](
+	ctx context.Context,
+	cfg CheckpointConfig,
+	src Replayable[X],
+	stages []CheckpointStage,
+	encode func(y any) []byte,
+) error {
+	if len(stages) == 0 {
+		return errors.New("pipeline: checkpointed pipeline needs at least one stage")
+	}
+	interval := int64(max(1, cfg.Interval))
+	last, err := cfg.Store.Load()
+	if err != nil {
+		return err
+	}
+	if last == nil {
+		last = &Checkpoint{}
+	} else {
+		if len(last.States) != len(stages) {
+			return fmt.Errorf("pipeline: checkpoint has %d stage states, pipeline has %d stages", len(last.States), len(stages))
+		}
+		for i, st := range stages {
+			if err := st.Restore(last.States[i]); err != nil {
+				return fmt.Errorf("pipeline: restore state of stage %d: %w", i, err)
+			}
+		}
+	}
+	sink, err := OpenFileSink(cfg.SinkPath, last.SinkLen)
+	if err != nil {
+		return err
+	}
+	defer sink.Close()
+
+	p := NewPipeline(ctx)
+	participants := len(stages) + 2 // 数据源、各阶段、终点
+	chans := make([]chan envelope[any], len(stages)+1)
+	for i := range chans {
+		chans[i] = make(chan envelope[any])
+	}
+	epochs := make(chan *epoch, 16) // 按顺序交给协调者
+
+	// 数据源：每 interval 条数据插入一个 barrier，并记录偏移量
+	p.Go(func(ctx context.Context) error {
+		defer close(chans[0])
+		defer close(epochs)
+		offset, next := last.Offset, last.Epoch+1
+		inject := func() bool {
+			e := &epoch{done: barrier.NewEasyBarrier(participants), synced: make(chan struct{})}
+			e.cp.Epoch, e.cp.Offset = next, offset
+			e.cp.States = make([][]byte, len(stages))
+			next++
+			e.done.Done()
+			select {
+			case <-ctx.Done():
+				return false
+			case epochs <- e:
+			}
+			return emit(ctx, chans[0], envelope[any]{barrier: e})
+		}
+		for x := range src(offset) {
+			if !emit(ctx, chans[0], envelope[any]{v: x}) {
+				return nil
+			}
+			offset++
+			if (offset-last.Offset)%interval == 0 && !inject() {
+				return nil
+			}
+		}
+		if (offset-last.Offset)%interval != 0 {
+			inject() // 最终检查点
+		}
+		return nil
+	})
+
+	// 有状态阶段：处理数据；遇到 barrier 时保存状态快照并转发标记
+	for i, stage := range stages {
+		in, out := chans[i], chans[i+1]
+		p.Go(func(ctx context.Context) error {
+			defer close(out)
+			for env := range in {
+				if env.barrier != nil {
+					state, err := stage.Snapshot()
+					if err != nil {
+						return fmt.Errorf("pipeline: snapshot state of stage %d: %w", i, err)
+					}
+					env.barrier.cp.States[i] = state
+					env.barrier.done.Done()
+					if !emit(ctx, out, env) {
+						return nil
+					}
+					continue
+				}
+				ys, err := stage.Process(env.v)
+				if err != nil {
+					return &StageError{Stage: fmt.Sprintf("checkpointed[%d]", i), Item: env.v, Attempts: 1, Err: err}
+				}
+				for _, y := range ys {
+					if !emit(ctx, out, envelope[any]{v: y}) {
+						return nil
+					}
+				}
+			}
+			return nil
+		})
+	}
+
+	// 终点：写入结果；遇到 barrier 时提交（刷盘）并记录文件长度
+	// 标记按顺序到达，终点确认时其余参与者都已确认，因此 Sync 不会阻塞
+	p.Go(func(ctx context.Context) error {
+		for env := range chans[len(stages)] {
+			if env.barrier != nil {
+				n, err := sink.Commit()
+				if err != nil {
+					return err
+				}
+				env.barrier.cp.SinkLen = n
+				env.barrier.done.Done()
+				env.barrier.done.Sync()
+				close(env.barrier.synced)
+				continue
+			}
+			if err := sink.Write(encode(env.v)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	// 协调者：按顺序等待每个周期的全部确认，然后写入检查点
+	// 流水线终止时放弃剩余的周期：它们的标记不会再到达终点，也没有 goroutine 在等待它们
+	p.Go(func(ctx context.Context) error {
+		for e := range epochs {
+			select {
+			case <-e.synced: // 已确认的周期优先保存，即使流水线正在终止
+			default:
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-e.synced:
+				}
+			}
+			if err := cfg.Store.Save(&e.cp); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err := p.Wait(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
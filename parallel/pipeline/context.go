@@ -0,0 +1,31 @@
+package pipeline
+
+import "context"
+
+// 以下各阶段与对应的 quit 版本行为一致，但由 ctx 控制终止：
+// ctx 结束后，阶段在任意一次阻塞的收发上都会立即退出并关闭输出通道，不会泄漏 goroutine
+
+// AddOnPipeContext 由 ctx 控制终止的 AddOnPipe
+func AddOnPipeContext[X, Y any](ctx context.Context, f func(X) Y, in <-chan X) chan Y {
+	return AddOnPipe(ctx.Done(), f, in)
+}
+
+// FanInContext 由 ctx 控制终止的 FanIn
+func FanInContext[X any](ctx context.Context, inputs ...<-chan X) chan X {
+	return FanIn(ctx.Done(), inputs...)
+}
+
+// FanOutContext 由 ctx 控制终止的 FanOut
+func FanOutContext[X any](ctx context.Context, in <-chan X, num int) []chan X {
+	return FanOut(ctx.Done(), in, num)
+}
+
+// TakeContext 由 ctx 控制终止的 Take
+func TakeContext[X any](ctx context.Context, n int, in <-chan X) chan X {
+	return Take(ctx.Done(), n, in)
+}
+
+// NewBroadcastContext 由 ctx 控制终止的 Broadcast
+func NewBroadcastContext[X any](ctx context.Context, in <-chan X) *Broadcast[X] {
+	return NewBroadcast(ctx.Done(), in)
+}
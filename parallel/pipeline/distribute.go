@@ -0,0 +1,185 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"reflect"
+)
+
+// Picker 分发策略：为数据 x 选择输出通道的下标（0 <= 下标 < len(outs)）
+// Distribute 只在一个 goroutine 中调用 Picker，有状态的 Picker 无需加锁，但不能在多个 Distribute 之间共用
+type Picker[X any] func(x X, outs []chan X) int
+
+// RoundRobin 轮询分发
+func RoundRobin[X any]() Picker[X] {
+	next := 0
+	return func(_ X, outs []chan X) int {
+		i := next % len(outs)
+		next = i + 1
+		return i
+	}
+}
+
+// LeastLoaded 分发到缓冲区中排队数据最少的输出通道，相同时轮流选择
+// 依据 len(chan) 判断负载；无缓冲（buf 为0）时各通道负载总是相同，退化为轮询
+func LeastLoaded[X any]() Picker[X] {
+	next := 0
+	return func(_ X, outs []chan X) int {
+		start := next % len(outs)
+		next = start + 1
+		best := start
+		for k := 1; k < len(outs); k++ {
+			i := (start + k) % len(outs)
+			if len(outs[i]) < len(outs[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+}
+
+// KeyHash 按 key 的哈希分发：key 相同的数据总是进入同一个输出通道，因此同一 key 的数据保持输入顺序
+// 字符串、布尔、整数和浮点类型（含以它们为底层类型的自定义类型）直接按值哈希，
+// 其他可比较类型按 %#v 的文本哈希，要求相等的 key 有相同的文本表示
+func KeyHash[X any, K comparable](key func(X) K) Picker[X] {
+	seed := maphash.MakeSeed()
+	return func(x X, outs []chan X) int {
+		return int(hashKey(seed, key(x)) % uint64(len(outs)))
+	}
+}
+
+// hashKey 计算可比较值 k 的哈希
+func hashKey[K comparable](seed maphash.Seed, k K) uint64 {
+	var h maphash.Hash
+	h.SetSeed(seed)
+	var buf [8]byte
+	v := reflect.ValueOf(k)
+	switch v.Kind() {
+	case reflect.String:
+		h.WriteString(v.String())
+	case reflect.Bool:
+		if v.Bool() {
+			h.WriteByte(1)
+		} else {
+			h.WriteByte(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		binary.LittleEndian.PutUint64(buf[:], uint64(v.Int()))
+		h.Write(buf[:])
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		binary.LittleEndian.PutUint64(buf[:], v.Uint())
+		h.Write(buf[:])
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f == 0 {
+			f = 0 // +0 与 -0 相等，统一哈希
+		}
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+		h.Write(buf[:])
+	default:
+		fmt.Fprintf(&h, "%#v", k)
+	}
+	return h.Sum64()
+}
+
+// Distribute 按 pick 把输入数据分发到 num 个输出通道，每条数据只进入其中一个
+// 由单个 goroutine 依次分发：选中的输出通道已满时会阻塞后续数据（保证 KeyHash 的同 key 有序）
+// 输入通道关闭或 ctx 结束后，所有输出通道关闭
+// 参数：
+//
+//	ctx: 控制终止
+//	in: 输入通道
+//	num: 输出通道数量
+//	buf: 每个输出通道的缓冲区大小
+//	pick: 分发策略（RoundRobin、LeastLoaded、KeyHash 或自定义）
+//
+// 返回：
+//
+//	输出通道切片
+func Distribute[X any](ctx context.Context, in <-chan X, num, buf int, pick Picker[X]) []chan X {
+	if num <= 0 {
+		panic("Distribute: 输出通道数量必须大于0")
+	}
+	outs := make([]chan X, num)
+	for i := range outs {
+		outs[i] = make(chan X, max(0, buf))
+	}
+
+	go func() {
+		defer func() {
+			for _, ch := range outs {
+				close(ch)
+			}
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case x, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case outs[pick(x, outs)] <- x:
+				}
+			}
+		}
+	}()
+
+	return outs
+}
+
+// Replicate 数据复制：每个输出通道都会收到输入的完整数据副本（按输入顺序）
+// 每条数据发送给所有输出通道后才读取下一条，因此最慢的下游决定整体速度；
+// 各输出通道之间的发送顺序不固定，下游可以按任意顺序读取而不会死锁
+// 输入通道关闭或 ctx 结束后，所有输出通道关闭
+func Replicate[X any](ctx context.Context, in <-chan X, num int) []chan X {
+	if num <= 0 {
+		panic("Replicate: 输出通道数量必须大于0")
+	}
+	outs := make([]chan X, num)
+	for i := range outs {
+		outs[i] = make(chan X)
+	}
+
+	go func() {
+		defer func() {
+			for _, ch := range outs {
+				close(ch)
+			}
+		}()
+		// cases[0] 监听 ctx，其余为各输出通道的发送；已发送的通道置为零值 Chan，不再参与选择
+		cases := make([]reflect.SelectCase, num+1)
+		cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+		for {
+			var x X
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				x = v
+			}
+			val := reflect.ValueOf(&x).Elem()
+			for i, ch := range outs {
+				cases[i+1] = reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(ch), Send: val}
+			}
+			for pending := num; pending > 0; pending-- {
+				chosen, _, _ := reflect.Select(cases)
+				if chosen == 0 {
+					return
+				}
+				cases[chosen].Chan = reflect.Value{}
+			}
+		}
+	}()
+
+	return outs
+}
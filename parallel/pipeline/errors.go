@@ -0,0 +1,202 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrorPolicy 阶段处理单条数据出错时的策略
+type ErrorPolicy int
+
+const (
+	PolicyAbort      ErrorPolicy = iota // 终止整条流水线（默认）
+	PolicySkip                          // 丢弃出错的数据，继续处理后续数据
+	PolicyRetry                         // 重试，超过 MaxRetries 次后按 Fallback 处理
+	PolicyDeadLetter                    // 把出错的数据连同错误发送到死信通道，继续处理后续数据
+)
+
+// StageError 阶段处理单条数据时产生的错误，携带出错的数据
+type StageError struct {
+	Stage    string // 阶段名
+	Item     any    // 出错的输入数据
+	Attempts int    // 已尝试次数
+	Err      error  // 原始错误（panic 会被转换为错误）
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("pipeline: stage %q failed after %d attempt(s): %v", e.Stage, e.Attempts, e.Err)
+}
+
+func (e *StageError) Unwrap() error { return e.Err }
+
+// StageOptions 阶段的错误处理配置
+type StageOptions struct {
+	Name       string             // 阶段名，出现在 StageError 中
+	Policy     ErrorPolicy        // 出错策略
+	MaxRetries int                // PolicyRetry 策略的最大重试次数（不包括首次执行）
+	RetryDelay time.Duration      // 两次尝试之间的等待时间
+	Fallback   ErrorPolicy        // 重试耗尽后的策略，为 PolicyRetry 时按 PolicyAbort 处理
+	DeadLetter chan<- *StageError // PolicyDeadLetter 策略（或 Fallback）使用的死信通道
+}
+
+// Pipeline 流水线句柄（类似 errgroup）：所有阶段共享同一个 ctx，任一阶段终止时取消整条流水线
+type Pipeline struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	errOnce sync.Once
+	err     error
+	skipped atomic.Int64
+}
+
+// NewPipeline 基于 parent 创建流水线句柄
+func NewPipeline(parent context.Context) *Pipeline {
+	ctx, cancel := context.WithCancel(parent)
+	return &Pipeline{ctx: ctx, cancel: cancel}
+}
+
+// Context 返回流水线共享的 ctx，流水线终止时被取消
+func (p *Pipeline) Context() context.Context { return p.ctx }
+
+// Go 在流水线中启动一个 goroutine，返回非nil错误时终止整条流水线
+func (p *Pipeline) Go(fn func(ctx context.Context) error) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		if err := fn(p.ctx); err != nil {
+			p.Abort(err)
+		}
+	}()
+}
+
+// Abort 以 err 终止流水线，只记录第一个错误
+func (p *Pipeline) Abort(err error) {
+	p.errOnce.Do(func() {
+		p.err = err
+		p.cancel()
+	})
+}
+
+// Wait 等待所有阶段退出，返回导致终止的第一个错误
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+	p.cancel()
+	return p.err
+}
+
+// Skipped 返回因 PolicySkip 策略被丢弃的数据条数
+func (p *Pipeline) Skipped() int64 { return p.skipped.Load() }
+
+// safeApply 调用 f，并把 panic 转换为错误
+func safeApply[X, Y any](ctx context.Context, f func(context.Context, X) (Y, error), x X) (y Y, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return f(ctx, x)
+}
+
+// handle 按策略处理失败的数据，返回 false 表示阶段应当退出
+func (p *Pipeline) handle(ctx context.Context, policy ErrorPolicy, opts StageOptions, se *StageError) bool {
+	switch policy {
+	case PolicySkip:
+		p.skipped.Add(1)
+		return true
+	case PolicyDeadLetter:
+		select {
+		case <-ctx.Done():
+			return false
+		case opts.DeadLetter <- se:
+			return true
+		}
+	default:
+		p.Abort(se)
+		return false
+	}
+}
+
+// AddOnPipeErr 可返回错误的管道节点：接收X类型输入，通过f转换为Y类型输出；出错时按 opts 的策略处理
+// f 中的 panic 会被恢复为错误；流水线终止时节点退出并关闭输出通道
+// 参数：
+//
+//	p: 流水线句柄（提供共享的 ctx 和错误收集）
+//	opts: 阶段名与出错策略
+//	f: 数据转换函数，接收 ctx，可返回错误
+//	in: 输入通道
+//
+// 返回：
+//
+//	输出通道（发送Y类型数据）
+func AddOnPipeErr[X, Y any](
+	p *Pipeline,
+	opts StageOptions,
+	f func(ctx context.Context, x X) (Y, error),
+	in <-chan X,
+) chan Y {
+	if (opts.Policy == PolicyDeadLetter || (opts.Policy == PolicyRetry && opts.Fallback == PolicyDeadLetter)) && opts.DeadLetter == nil {
+		panic("AddOnPipeErr: PolicyDeadLetter 策略需要死信通道")
+	}
+	attempts := 1
+	fallback := opts.Policy
+	if opts.Policy == PolicyRetry {
+		attempts += max(0, opts.MaxRetries)
+		fallback = opts.Fallback
+	}
+
+	out := make(chan Y)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer close(out)
+		ctx := p.ctx
+
+		for {
+			var x X
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				x = v
+			}
+
+			var (
+				y   Y
+				err error
+				n   int
+			)
+			for n = 1; n <= attempts; n++ {
+				if y, err = safeApply(ctx, f, x); err == nil {
+					break
+				}
+				if n < attempts && opts.RetryDelay > 0 {
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(opts.RetryDelay):
+					}
+				}
+			}
+			if err != nil {
+				se := &StageError{Stage: opts.Name, Item: x, Attempts: min(n, attempts), Err: err}
+				if !p.handle(ctx, fallback, opts, se) {
+					return
+				}
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case out <- y:
+			}
+		}
+	}()
+	return out
+}
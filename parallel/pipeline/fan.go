@@ -1,7 +1,6 @@
 package pipeline
 
 import (
-	"fmt"
 	"sync"
 )
 
@@ -38,20 +37,20 @@ func FanIn[X any](q <-chan struct{}, inputs ...<-chan X) chan X {
     go func() {
         wg.Wait()
         close(out)
-        fmt.Println("FanIn: 所有输入处理完毕，关闭输出通道")
     }()
 
     return out
 }
 
 
-// FanOut 数据分发：将单一输入通道的数据复制到多个输出通道
+// FanOut 数据分发：num 个 goroutine 竞争读取同一个输入通道，每条数据只会进入其中一个输出通道（不保证顺序）
+// 需要每个输出都收到完整副本时使用 Replicate，需要指定分发策略（轮询、最空闲、按 key）时使用 Distribute
 // 参数：
 //   q: 终止信号通道（关闭时组件退出）
 //   in: 输入通道（单一数据源）
 //   num: 输出通道数量
 // 返回：
-//   输出通道切片（每个通道都会收到输入的完整数据副本）
+//   输出通道切片（各通道收到的数据互不重复，合起来为输入的全部数据）
 func FanOut[X any](q <-chan struct{}, in <-chan X, num int) []chan X {
     if num <= 0 {
         panic("FanOut: 输出通道数量必须大于0")
@@ -63,18 +62,12 @@ func FanOut[X any](q <-chan struct{}, in <-chan X, num int) []chan X {
         outs[i] = make(chan X)
     }
 
-    var wg sync.WaitGroup
-    wg.Add(num) // 每个输出通道对应一个goroutine
-
-    // 为每个输出通道启动goroutine，负责复制数据
+    // 为每个输出通道启动goroutine，负责转发数据
     for i := 0; i < num; i++ {
         go func(outChan chan<- X) {
-            defer func() {
-                wg.Done()
-                close(outChan) // 确保每个输出通道最终会关闭
-            }()
+            defer close(outChan) // 确保每个输出通道最终会关闭
 
-            // 从输入通道读取数据，复制到当前输出通道
+            // 从输入通道读取数据，转发到当前输出通道
             for {
                 select {
                 case <-q: // 响应终止信号
@@ -94,11 +87,5 @@ func FanOut[X any](q <-chan struct{}, in <-chan X, num int) []chan X {
         }(outs[i])
     }
 
-    // 可选：等待所有输出goroutine结束后打印日志（非必需）
-    go func() {
-        wg.Wait()
-        fmt.Printf("FanOut: 所有%d个输出通道已处理完毕并关闭\n", num)
-    }()
-
     return outs
 }
@@ -0,0 +1,202 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/leoxiang66/go-patterns/container/deque"
+)
+
+// 流量控制阶段：输入通道关闭或 ctx 结束后关闭输出通道，任何阻塞的收发都会响应 ctx
+
+// ErrTimeout Timeout 阶段在 d 时间内没有收到数据
+var ErrTimeout = errors.New("pipeline: no item within timeout")
+
+// RateLimit 限速：任意 interval 时长内最多放行一条数据，多余的数据阻塞上游
+func RateLimit[X any](ctx context.Context, in <-chan X, interval time.Duration) chan X {
+	return Throttle(ctx, in, 1, interval)
+}
+
+// Throttle 节流：任意 interval 时长内最多放行 n 条数据（滑动窗口），超出的数据等到窗口滑过（阻塞上游，不丢弃）
+// 记录最近 n 条数据被下游接收的时刻，第 n+1 条数据要等到其中最早的一次超过 interval 才能放行，因此空闲后的突发也不会超限
+func Throttle[X any](ctx context.Context, in <-chan X, n int, interval time.Duration) chan X {
+	if interval <= 0 {
+		panic("Throttle: interval 必须大于0")
+	}
+	n = max(1, n)
+	out := make(chan X)
+	go func() {
+		defer close(out)
+		sent, _ := deque.NewBounded[time.Time](n, true) // 最近 n 次放行的时刻，满后覆盖最早的
+		timer := time.NewTimer(0)
+		timer.Stop()
+		defer timer.Stop()
+		for {
+			var x X
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				x = v
+			}
+			if sent.Full() {
+				oldest, _ := sent.Front()
+				if wait := time.Until(oldest.Add(interval)); wait > 0 {
+					timer.Reset(wait)
+					select {
+					case <-ctx.Done():
+						return
+					case <-timer.C:
+					}
+				}
+			}
+			if !emit(ctx, out, x) {
+				return
+			}
+			// 以下游实际接收的时刻计时：下游较慢时 emit 会阻塞，提前记录会让后续数据放行过早
+			sent.PushBack(time.Now())
+		}
+	}()
+	return out
+}
+
+// Debounce 防抖：收到数据后静默 d 时间内没有新数据才发出，期间的新数据覆盖旧数据；输入关闭时发出尚未发出的最后一条
+func Debounce[X any](ctx context.Context, in <-chan X, d time.Duration) chan X {
+	if d <= 0 {
+		panic("Debounce: d 必须大于0")
+	}
+	out := make(chan X)
+	go func() {
+		defer close(out)
+		timer := time.NewTimer(d)
+		timer.Stop()
+		defer timer.Stop()
+		var (
+			last    X
+			pending bool
+		)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+				pending = false
+				if !emit(ctx, out, last) {
+					return
+				}
+			case x, ok := <-in:
+				if !ok {
+					if pending {
+						emit(ctx, out, last)
+					}
+					return
+				}
+				last, pending = x, true
+				timer.Reset(d)
+			}
+		}
+	}()
+	return out
+}
+
+// Sample 采样：每隔 interval 发出这段时间内收到的最新一条数据，期间没有新数据则不发出；输入关闭时发出尚未发出的最新数据
+func Sample[X any](ctx context.Context, in <-chan X, interval time.Duration) chan X {
+	if interval <= 0 {
+		panic("Sample: interval 必须大于0")
+	}
+	out := make(chan X)
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var (
+			latest  X
+			pending bool
+		)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if !pending {
+					continue
+				}
+				pending = false
+				if !emit(ctx, out, latest) {
+					return
+				}
+			case x, ok := <-in:
+				if !ok {
+					if pending {
+						emit(ctx, out, latest)
+					}
+					return
+				}
+				latest, pending = x, true
+			}
+		}
+	}()
+	return out
+}
+
+// Distinct 去重：只放行第一次出现的数据（需记住所有出现过的数据）
+func Distinct[X comparable](ctx context.Context, in <-chan X) chan X {
+	seen := make(map[X]struct{})
+	return Filter(ctx, in, func(x X) bool {
+		if _, ok := seen[x]; ok {
+			return false
+		}
+		seen[x] = struct{}{}
+		return true
+	})
+}
+
+// DistinctUntilChanged 去除连续重复：只放行与上一条不同的数据
+func DistinctUntilChanged[X comparable](ctx context.Context, in <-chan X) chan X {
+	var (
+		prev  X
+		first = true
+	)
+	return Filter(ctx, in, func(x X) bool {
+		if !first && x == prev {
+			return false
+		}
+		prev, first = x, false
+		return true
+	})
+}
+
+// Timeout 超时检测：原样转发数据，若距上一条数据（或启动时）超过 d 仍未收到新数据，
+// 向错误通道发送 ErrTimeout 并关闭输出通道；错误通道容量为1，在输出通道关闭后关闭
+func Timeout[X any](ctx context.Context, in <-chan X, d time.Duration) (chan X, <-chan error) {
+	out := make(chan X)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(out)
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+				errc <- ErrTimeout
+				return
+			case x, ok := <-in:
+				if !ok {
+					return
+				}
+				if !emit(ctx, out, x) {
+					return
+				}
+				timer.Reset(d) // 下游处理数据的时间不计入超时
+			}
+		}
+	}()
+	return out, errc
+}
@@ -0,0 +1,89 @@
+package pipeline
+
+import "context"
+
+// 流处理辅助阶段：与 Take 一致，输入通道关闭或 ctx 结束后关闭输出通道，阻塞的收发都会响应 ctx
+
+// Pair Zip 输出的数据对
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// forward 逐条读取 in 并调用 fn，fn 返回 false 时结束；用于实现各辅助阶段
+func forward[X, Y any](ctx context.Context, in <-chan X, fn func(x X, out chan<- Y) bool) chan Y {
+	out := make(chan Y)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case x, ok := <-in:
+				if !ok || !fn(x, out) {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// Filter 只放行满足 pred 的数据
+func Filter[X any](ctx context.Context, in <-chan X, pred func(x X) bool) chan X {
+	return forward(ctx, in, func(x X, out chan<- X) bool {
+		return !pred(x) || emit(ctx, out, x)
+	})
+}
+
+// Skip 丢弃前 n 条数据，之后原样放行
+func Skip[X any](ctx context.Context, in <-chan X, n int) chan X {
+	skipped := 0
+	return forward(ctx, in, func(x X, out chan<- X) bool {
+		if skipped < n {
+			skipped++
+			return true
+		}
+		return emit(ctx, out, x)
+	})
+}
+
+// TakeWhile 放行数据直到第一条不满足 pred 的数据，随后立即关闭输出（不再读取上游）
+func TakeWhile[X any](ctx context.Context, in <-chan X, pred func(x X) bool) chan X {
+	return forward(ctx, in, func(x X, out chan<- X) bool {
+		return pred(x) && emit(ctx, out, x)
+	})
+}
+
+// FlatMap 把每条数据展开为多条：f 返回的切片按顺序依次发出
+func FlatMap[X, Y any](ctx context.Context, in <-chan X, f func(x X) []Y) chan Y {
+	return forward(ctx, in, func(x X, out chan<- Y) bool {
+		for _, y := range f(x) {
+			if !emit(ctx, out, y) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Scan 累积：以 init 为初值，对每条数据调用 f 并发出当前的累积值
+func Scan[X, A any](ctx context.Context, in <-chan X, init A, f func(acc A, x X) A) chan A {
+	acc := init
+	return forward(ctx, in, func(x X, out chan<- A) bool {
+		acc = f(acc, x)
+		return emit(ctx, out, acc)
+	})
+}
+
+// Zip 把两个输入按顺序一一配对，任一输入关闭后关闭输出（另一输入多余的数据不再读取）
+func Zip[A, B any](ctx context.Context, a <-chan A, b <-chan B) chan Pair[A, B] {
+	return forward(ctx, a, func(x A, out chan<- Pair[A, B]) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case y, ok := <-b:
+			return ok && emit(ctx, out, Pair[A, B]{First: x, Second: y})
+		}
+	})
+}
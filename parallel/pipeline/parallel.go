@@ -0,0 +1,109 @@
+package pipeline
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/leoxiang66/go-patterns/internal/reorder"
+)
+
+// parallelConfig ParallelPipe 的配置
+type parallelConfig struct {
+	ordered bool // 是否按输入顺序输出
+	buffer  int  // 输出通道缓冲区大小
+	window  int  // 有序模式下已派发但尚未发出的数据上限（重排缓冲区大小）
+}
+
+// ParallelOption ParallelPipe 的配置项
+type ParallelOption func(*parallelConfig)
+
+// Ordered 按输入顺序输出结果；window 为重排缓冲区上限（小于 workers 时按 workers 计），
+// 慢数据会阻塞后续结果的发出，window 越大越能容忍处理时间的抖动
+func Ordered(window int) ParallelOption {
+	return func(c *parallelConfig) {
+		c.ordered = true
+		c.window = window
+	}
+}
+
+// BufferSize 设置输出通道的缓冲区大小（默认无缓冲）
+func BufferSize(n int) ParallelOption {
+	return func(c *parallelConfig) {
+		c.buffer = max(0, n)
+	}
+}
+
+// ParallelPipe 并行管道节点：用 workers 个 goroutine 并发执行 f，适合 CPU 密集型阶段
+// 默认无序输出（结果一就绪即发出，吞吐最高）；传入 Ordered 时按输入顺序输出
+// 输入通道关闭且所有结果发出后，或 ctx 结束后，输出通道关闭
+// 参数：
+//
+//	ctx: 控制终止
+//	workers: 并发数，小于等于0时取 GOMAXPROCS
+//	f: 数据转换函数
+//	in: 输入通道
+//	opts: Ordered、BufferSize 等配置项
+//
+// 返回：
+//
+//	输出通道（发送Y类型数据）
+func ParallelPipe[X, Y any](
+	ctx context.Context,
+	workers int,
+	f func(X) Y,
+	in <-chan X,
+	opts ...ParallelOption,
+) chan Y {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	var cfg parallelConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	out := make(chan Y, cfg.buffer)
+	if !cfg.ordered {
+		parallelUnordered(ctx, workers, f, in, out)
+	} else {
+		parallelOrdered(ctx, workers, max(cfg.window, workers), f, in, out)
+	}
+	return out
+}
+
+// parallelUnordered 所有 worker 直接从 in 读取、向 out 写入
+func parallelUnordered[X, Y any](ctx context.Context, workers int, f func(X) Y, in <-chan X, out chan<- Y) {
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case x, ok := <-in:
+					if !ok {
+						return
+					}
+					y := f(x)
+					select {
+					case <-ctx.Done():
+						return
+					case out <- y:
+					}
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+}
+
+// parallelOrdered 派发时给数据编号，结果经重排缓冲区按编号顺序发出，实现位于 internal/reorder
+func parallelOrdered[X, Y any](ctx context.Context, workers, window int, f func(X) Y, in <-chan X, out chan<- Y) {
+	reorder.Map(ctx, workers, window, f, in, out)
+}
@@ -0,0 +1,1101 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"io"
+	"iter"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	msgqueue "github.com/leoxiang66/go-patterns/container/msgQueue"
+)
+
+// feed 把 xs 依次发送到返回的通道，发送完毕后关闭
+func feed[X any](xs ...X) chan X {
+	ch := make(chan X)
+	go func() {
+		defer close(ch)
+		for _, x := range xs {
+			ch <- x
+		}
+	}()
+	return ch
+}
+
+// collect 读取通道直到关闭
+func collect[X any](ch <-chan X) []X {
+	var out []X
+	for x := range ch {
+		out = append(out, x)
+	}
+	return out
+}
+
+var errOdd = errors.New("odd")
+
+func failOdd(ctx context.Context, x int) (int, error) {
+	if x%2 == 1 {
+		return 0, errOdd
+	}
+	return x * 10, nil
+}
+
+func TestAddOnPipeErrSkipAndDeadLetter(t *testing.T) {
+	p := NewPipeline(context.Background())
+	dead := make(chan *StageError, 10)
+	skipped := AddOnPipeErr(p, StageOptions{Name: "skip", Policy: PolicySkip}, failOdd, feed(1, 2, 3, 4))
+	out := AddOnPipeErr(p, StageOptions{Name: "dl", Policy: PolicyDeadLetter, DeadLetter: dead},
+		func(ctx context.Context, x int) (int, error) {
+			if x == 40 {
+				panic("boom")
+			}
+			return x, nil
+		}, skipped)
+
+	got := collect(out)
+	if err := p.Wait(); err != nil {
+		t.Fatalf("Wait = %v", err)
+	}
+	if len(got) != 1 || got[0] != 20 || p.Skipped() != 2 {
+		t.Errorf("got %v, skipped %d", got, p.Skipped())
+	}
+	if len(dead) != 1 {
+		t.Fatalf("dead letters = %d, want 1", len(dead))
+	}
+	if se := <-dead; se.Item != 40 || se.Stage != "dl" {
+		t.Errorf("dead letter = %+v", se)
+	}
+}
+
+func TestAddOnPipeErrRetryThenAbort(t *testing.T) {
+	p := NewPipeline(context.Background())
+	calls := 0
+	out := AddOnPipeErr(p, StageOptions{Name: "retry", Policy: PolicyRetry, MaxRetries: 2},
+		func(ctx context.Context, x int) (int, error) {
+			calls++
+			return 0, errOdd
+		}, feed(1, 2, 3))
+	collect(out)
+
+	var se *StageError
+	err := p.Wait()
+	if !errors.As(err, &se) || !errors.Is(err, errOdd) || se.Item != 1 || se.Attempts != 3 {
+		t.Fatalf("Wait = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+// checkNoLeak 等待 goroutine 数回落到 before 以下，超时则失败（简化版 goleak）
+func checkNoLeak(t *testing.T, before int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("goroutine leak: %d > %d\n%s", runtime.NumGoroutine(), before, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// counter 无限产生递增整数，直到 ctx 结束
+func counter(ctx context.Context) chan int {
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for i := 0; ; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- i:
+			}
+		}
+	}()
+	return ch
+}
+
+func TestContextStagesNoLeak(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	doubled := AddOnPipeContext(ctx, func(x int) int { return x * 2 }, counter(ctx))
+	outs := FanOutContext(ctx, doubled, 3)
+	merged := FanInContext(ctx, outs[0], outs[1], outs[2])
+	b := NewBroadcastContext(ctx, TakeContext(ctx, 1000, merged))
+	sub1, sub2 := b.Subscribe(), b.Subscribe()
+	go b.Run()
+
+	// 只读取少量数据后取消：所有阶段都阻塞在发送上，必须响应取消
+	<-sub1
+	<-sub2
+	cancel()
+	for range sub1 {
+	}
+	for range sub2 {
+	}
+	checkNoLeak(t, before)
+}
+
+func TestTakeClosesWithoutWaitingForUpstream(t *testing.T) {
+	in := make(chan int)
+	quit := make(chan struct{})
+	defer close(quit)
+	out := Take(quit, 1, in)
+	go func() { in <- 1 }()
+	<-out
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("Take emitted more than n items")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Take did not close after n items")
+	}
+}
+
+func TestParallelPipeOrdered(t *testing.T) {
+	ctx := context.Background()
+	xs := make([]int, 200)
+	for i := range xs {
+		xs[i] = i
+	}
+	// 处理时间与数据相关，使结果乱序到达
+	slowSquare := func(x int) int {
+		time.Sleep(time.Duration(x%7) * 100 * time.Microsecond)
+		return x * x
+	}
+	got := collect(ParallelPipe(ctx, 8, slowSquare, feed(xs...), Ordered(16), BufferSize(4)))
+	if len(got) != len(xs) {
+		t.Fatalf("got %d results, want %d", len(got), len(xs))
+	}
+	for i, y := range got {
+		if y != i*i {
+			t.Fatalf("got[%d] = %d, want %d", i, y, i*i)
+		}
+	}
+}
+
+func TestParallelPipeUnordered(t *testing.T) {
+	ctx := context.Background()
+	xs := make([]int, 200)
+	for i := range xs {
+		xs[i] = i
+	}
+	got := collect(ParallelPipe(ctx, 4, func(x int) int { return x + 1 }, feed(xs...)))
+	seen := make(map[int]bool, len(got))
+	for _, y := range got {
+		seen[y] = true
+	}
+	if len(got) != len(xs) || len(seen) != len(xs) {
+		t.Fatalf("got %d results (%d distinct), want %d", len(got), len(seen), len(xs))
+	}
+}
+
+func TestParallelPipeCancelNoLeak(t *testing.T) {
+	for _, opts := range [][]ParallelOption{nil, {Ordered(4)}} {
+		before := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+		out := ParallelPipe(ctx, 4, func(x int) int { return x }, counter(ctx), opts...)
+		for i := 0; i < 10; i++ {
+			<-out
+		}
+		cancel()
+		for range out {
+		}
+		checkNoLeak(t, before)
+	}
+}
+
+func TestBuilderGraph(t *testing.T) {
+	b := NewBuilder(4)
+	src := AddSource(b, "src", func(ctx context.Context, emit func(int) bool) error {
+		for i := 1; i <= 100; i++ {
+			if !emit(i) {
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+	sq := AddMap(b, "square", src, 4, func(_ context.Context, x int) (int, error) { return x * x, nil })
+	parts := AddFanOut(b, "split", sq, 2)
+	a := AddMap(b, "a", parts[0], 1, func(_ context.Context, x int) (int, error) { return x, nil })
+	c := AddMap(b, "c", parts[1], 1, func(_ context.Context, x int) (int, error) { return x, nil })
+	merged := AddMerge(b, "merge", a, c)
+	sum := 0
+	AddSink(b, "sum", merged, func(_ context.Context, x int) error {
+		sum += x
+		return nil
+	})
+
+	g, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 338350 {
+		t.Fatalf("sum = %d, want 338350", sum)
+	}
+	ms := g.Metrics()
+	if ms[0].Name != "src" || ms[0].Out != 100 || ms[len(ms)-1].In != 100 {
+		t.Fatalf("unexpected metrics %+v", ms)
+	}
+	if ms[3].In+ms[4].In != 100 {
+		t.Fatalf("fan-out lost items: %+v", ms)
+	}
+	if g.Start(context.Background()) == nil {
+		t.Fatal("second Start should fail")
+	}
+	if _, err := b.Build(); err == nil {
+		t.Fatal("second Build should fail: the graph shares the builder's channels")
+	}
+}
+
+func TestBuilderValidate(t *testing.T) {
+	b := NewBuilder(0)
+	src := AddSource(b, "src", func(ctx context.Context, emit func(int) bool) error { return nil })
+	AddMap(b, "dangling", src, 1, func(_ context.Context, x int) (int, error) { return x, nil })
+	if _, err := b.Build(); err == nil {
+		t.Fatal("expected error for unconsumed output")
+	}
+
+	b = NewBuilder(0)
+	src = AddSource(b, "src", func(ctx context.Context, emit func(int) bool) error { return nil })
+	AddSink(b, "s1", src, func(context.Context, int) error { return nil })
+	AddSink(b, "s2", src, func(context.Context, int) error { return nil })
+	if _, err := b.Build(); err == nil {
+		t.Fatal("expected error for output consumed twice")
+	}
+
+	other := NewBuilder(0)
+	AddSink(other, "sink", src, func(context.Context, int) error { return nil })
+	if _, err := other.Build(); err == nil {
+		t.Fatal("expected error for node from another builder")
+	}
+}
+
+func TestBuilderStageErrorAborts(t *testing.T) {
+	before := runtime.NumGoroutine()
+	b := NewBuilder(0)
+	src := AddSource(b, "src", func(ctx context.Context, emit func(int) bool) error {
+		for i := 0; emit(i); i++ {
+		}
+		return ctx.Err()
+	})
+	m := AddMap(b, "fail", src, 2, func(_ context.Context, x int) (int, error) {
+		if x == 10 {
+			return 0, errOdd
+		}
+		return x, nil
+	})
+	AddSink(b, "drop", m, func(context.Context, int) error { return nil })
+	g, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Start(context.Background())
+	err = g.Wait()
+	var se *StageError
+	if !errors.As(err, &se) || se.Stage != "fail" || se.Item != 10 || !errors.Is(err, errOdd) {
+		t.Fatalf("Wait() = %v, want StageError from stage fail", err)
+	}
+	checkNoLeak(t, before)
+}
+
+// TestBuilderConcurrentStartStop Stop 与 Start 并发调用不应产生数据竞争（需 -race 检测）
+func TestBuilderConcurrentStartStop(t *testing.T) {
+	b := NewBuilder(0)
+	src := AddSource(b, "src", func(ctx context.Context, emit func(int) bool) error {
+		for i := 0; emit(i); i++ {
+		}
+		return ctx.Err()
+	})
+	AddSink(b, "drop", src, func(context.Context, int) error { return nil })
+	g, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	go func() {
+		defer close(started)
+		g.Start(context.Background())
+	}()
+	for {
+		g.Stop()
+		select {
+		case <-started:
+		default:
+			runtime.Gosched()
+			continue
+		}
+		break
+	}
+	g.Stop()
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+}
+
+func TestBuilderGracefulStop(t *testing.T) {
+	b := NewBuilder(8)
+	src := AddSource(b, "src", func(ctx context.Context, emit func(int) bool) error {
+		for i := 0; emit(i); i++ {
+		}
+		return ctx.Err()
+	})
+	slow := AddMap(b, "slow", src, 2, func(_ context.Context, x int) (int, error) {
+		time.Sleep(100 * time.Microsecond)
+		return x, nil
+	})
+	var got atomic.Int64
+	AddSink(b, "count", slow, func(context.Context, int) error {
+		got.Add(1)
+		return nil
+	})
+	g, _ := b.Build()
+	g.Start(context.Background())
+	time.Sleep(20 * time.Millisecond)
+	g.Stop()
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Wait() after Stop = %v", err)
+	}
+	// 优雅停止：源发出的每条数据都被处理完
+	if emitted := g.Metrics()[0].Out; emitted == 0 || got.Load() != emitted {
+		t.Fatalf("sink got %d items, source emitted %d", got.Load(), emitted)
+	}
+}
+
+// drainAll 并发读取所有输出通道，返回每个通道收到的数据
+func drainAll[X any](outs []chan X) [][]X {
+	res := make([][]X, len(outs))
+	var wg sync.WaitGroup
+	for i, ch := range outs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res[i] = collect(ch)
+		}()
+	}
+	wg.Wait()
+	return res
+}
+
+func TestDistribute(t *testing.T) {
+	ctx := context.Background()
+	xs := make([]int, 90)
+	for i := range xs {
+		xs[i] = i
+	}
+
+	rr := drainAll(Distribute(ctx, feed(xs...), 3, 0, RoundRobin[int]()))
+	for i, got := range rr {
+		if len(got) != 30 || got[0] != i || got[29] != 87+i {
+			t.Fatalf("round robin output %d = %v", i, got)
+		}
+	}
+
+	total := 0
+	for _, got := range drainAll(Distribute(ctx, feed(xs...), 4, 2, LeastLoaded[int]())) {
+		total += len(got)
+	}
+	if total != len(xs) {
+		t.Fatalf("least loaded delivered %d items, want %d", total, len(xs))
+	}
+
+	// 无缓冲时 LeastLoaded 退化为轮询，不会全部发往下标0
+	for i, got := range drainAll(Distribute(ctx, feed(xs...), 3, 0, LeastLoaded[int]())) {
+		if len(got) != 30 {
+			t.Fatalf("unbuffered least loaded output %d got %d items, want 30", i, len(got))
+		}
+	}
+
+	type event struct {
+		key string
+		seq int
+	}
+	events := make([]event, 300)
+	for i := range events {
+		events[i] = event{key: string(rune('a' + i%7)), seq: i}
+	}
+	outs := Distribute(ctx, feed(events...), 4, 1, KeyHash(func(e event) string { return e.key }))
+	owner := map[string]int{}
+	for i, got := range drainAll(outs) {
+		last := map[string]int{}
+		for _, e := range got {
+			if o, ok := owner[e.key]; ok && o != i {
+				t.Fatalf("key %q went to outputs %d and %d", e.key, o, i)
+			}
+			owner[e.key] = i
+			if s, ok := last[e.key]; ok && s > e.seq {
+				t.Fatalf("key %q out of order: %d after %d", e.key, e.seq, s)
+			}
+			last[e.key] = e.seq
+		}
+	}
+	if len(owner) != 7 {
+		t.Fatalf("saw %d keys, want 7", len(owner))
+	}
+
+	// 非字符串 key：整数与结构体 key 无需先转成字符串
+	type region struct {
+		zone string
+		id   int
+	}
+	byInt := KeyHash(func(e event) int { return e.seq % 5 })
+	byStruct := KeyHash(func(e event) region { return region{e.key, e.seq % 2} })
+	chans := make([]chan event, 8)
+	for i := 0; i < 100; i++ {
+		a, b := event{key: "x", seq: i}, event{key: "x", seq: i + 10}
+		if byInt(a, chans) != byInt(b, chans) || byStruct(a, chans) != byStruct(b, chans) {
+			t.Fatalf("equal keys of %v and %v picked different outputs", a, b)
+		}
+	}
+	seed := maphash.MakeSeed()
+	if hashKey(seed, 0.0) != hashKey(seed, math.Copysign(0, -1)) {
+		t.Fatal("+0 and -0 hashed differently")
+	}
+}
+
+func TestReplicate(t *testing.T) {
+	outs := Replicate(context.Background(), feed(1, 2, 3), 3)
+	// 每轮按逆序读取各输出也不会死锁
+	for want := 1; want <= 3; want++ {
+		for i := len(outs) - 1; i >= 0; i-- {
+			if got := <-outs[i]; got != want {
+				t.Fatalf("output %d got %d, want %d", i, got, want)
+			}
+		}
+	}
+	for i, ch := range outs {
+		if _, ok := <-ch; ok {
+			t.Fatalf("output %d not closed", i)
+		}
+	}
+
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	outs = Replicate(ctx, counter(ctx), 2)
+	<-outs[0]
+	cancel()
+	drainAll(outs)
+	checkNoLeak(t, before)
+}
+
+func ExampleAddOnPipe() {
+	quit := make(chan struct{})
+	defer close(quit)
+
+	input := feed(1, 2, 3)
+	pipe := AddOnPipe(quit, func(x int) string { return fmt.Sprintf("处理后的数据：%d", x) }, input)
+	for output := range pipe {
+		fmt.Println(output)
+	}
+	// Output:
+	// 处理后的数据：1
+	// 处理后的数据：2
+	// 处理后的数据：3
+}
+
+func TestBroadcastSlowSubscriberPolicies(t *testing.T) {
+	in := make(chan int)
+	b := NewBroadcast(nil, in)
+	b.KeepHistory(3)
+
+	fast := b.Subscribe()
+	newest := b.SubscribeWith(SubscribeOptions{Buffer: 2, Policy: DropNewest})
+	oldest := b.SubscribeWith(SubscribeOptions{Buffer: 2, Policy: DropOldest})
+	disc := b.SubscribeWith(SubscribeOptions{Buffer: 1, Policy: Disconnect})
+	go b.Run()
+
+	// 只有 fast 在读取，其余订阅者都不读，广播不应被它们拖住
+	for i := 1; i <= 5; i++ {
+		in <- i
+		if got := <-fast; got != i {
+			t.Fatalf("fast got %d, want %d", got, i)
+		}
+	}
+	// 输入关闭后 Run 依次处理完所有订阅者再关闭通道
+	close(in)
+	if _, ok := <-fast; ok {
+		t.Fatal("fast not closed")
+	}
+
+	if got := collect(newest.C()); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("DropNewest kept %v, want [1 2]", got)
+	}
+	if st := newest.Stats(); st.Delivered != 2 || st.Dropped != 3 {
+		t.Fatalf("DropNewest stats %+v", st)
+	}
+	if got := collect(oldest.C()); len(got) != 2 || got[0] != 4 || got[1] != 5 {
+		t.Fatalf("DropOldest kept %v, want [4 5]", got)
+	}
+	if st := oldest.Stats(); st.Dropped != 3 {
+		t.Fatalf("DropOldest stats %+v", st)
+	}
+	<-disc.Done()
+	if got := collect(disc.C()); len(got) != 1 || got[0] != 1 || !disc.Stats().Disconnected {
+		t.Fatalf("Disconnect got %v, stats %+v", got, disc.Stats())
+	}
+
+	// 迟到的订阅者重放最近的历史消息
+	late := b.SubscribeWith(SubscribeOptions{Replay: 10})
+	if got := collect(late.C()); len(got) != 3 || got[0] != 3 || got[2] != 5 {
+		t.Fatalf("replay got %v, want [3 4 5]", got)
+	}
+
+	// 缩小历史容量时只保留最新的消息
+	b.KeepHistory(2)
+	if got := collect(b.SubscribeWith(SubscribeOptions{Replay: 10}).C()); len(got) != 2 || got[0] != 4 || got[1] != 5 {
+		t.Fatalf("replay after shrink got %v, want [4 5]", got)
+	}
+}
+
+func TestBroadcastUnsubscribe(t *testing.T) {
+	in := make(chan int)
+	quit := make(chan struct{})
+	b := NewBroadcast(quit, in)
+	keep := b.Subscribe()
+	blocked := b.SubscribeWith(SubscribeOptions{})
+	go b.Run()
+
+	// blocked 不读取：广播阻塞在它身上，取消订阅后应立即恢复
+	in <- 1
+	<-keep
+	go func() { in <- 2 }()
+	time.Sleep(10 * time.Millisecond)
+	blocked.Unsubscribe()
+	blocked.Unsubscribe()
+	if got := <-keep; got != 2 {
+		t.Fatalf("got %d, want 2", got)
+	}
+	select {
+	case <-blocked.Done():
+	default:
+		t.Fatal("Done not closed after Unsubscribe")
+	}
+
+	close(quit)
+	if _, ok := <-keep; ok {
+		t.Fatal("subscriber not closed after quit")
+	}
+	after := b.SubscribeWith(SubscribeOptions{})
+	if _, ok := <-after.C(); ok {
+		t.Fatal("subscription after Run finished should be closed")
+	}
+}
+
+func TestBatch(t *testing.T) {
+	ctx := context.Background()
+	got := collect(Batch(ctx, feed(1, 2, 3, 4, 5, 6, 7), 3, 0))
+	if len(got) != 3 || len(got[0]) != 3 || len(got[2]) != 1 || got[2][0] != 7 {
+		t.Fatalf("Batch by count = %v", got)
+	}
+
+	// 不足 n 条时按 maxWait 发出
+	in := make(chan int)
+	out := Batch(ctx, in, 100, 20*time.Millisecond)
+	in <- 1
+	in <- 2
+	select {
+	case b := <-out:
+		if len(b) != 2 {
+			t.Fatalf("Batch by time = %v", b)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Batch did not flush after maxWait")
+	}
+	close(in)
+	if _, ok := <-out; ok {
+		t.Fatal("Batch emitted an empty batch")
+	}
+}
+
+func TestTimeWindows(t *testing.T) {
+	ctx := context.Background()
+	xs := make([]int, 50)
+	for i := range xs {
+		xs[i] = i
+	}
+	// 滚动窗口不丢不重
+	n := 0
+	for _, w := range collect(TumblingWindow(ctx, feed(xs...), 5*time.Millisecond)) {
+		n += len(w)
+	}
+	if n != len(xs) {
+		t.Fatalf("TumblingWindow emitted %d items, want %d", n, len(xs))
+	}
+
+	// 滑动窗口：数据停止到达后仍在后续窗口中出现，直到滑出 size
+	in := make(chan int)
+	out := SlidingWindow(ctx, in, 50*time.Millisecond, 10*time.Millisecond)
+	in <- 1
+	first, second := <-out, <-out
+	if len(first) != 1 || len(second) != 1 {
+		t.Fatalf("SlidingWindow windows = %v, %v", first, second)
+	}
+	close(in)
+	collect(out)
+}
+
+func TestDurationValidation(t *testing.T) {
+	mustPanic := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s did not panic", name)
+			}
+		}()
+		fn()
+	}
+	ctx := context.Background()
+	in := make(chan int)
+	mustPanic("TumblingWindow(0)", func() { TumblingWindow(ctx, in, 0) })
+	mustPanic("SlidingWindow(size=0)", func() { SlidingWindow(ctx, in, 0, time.Second) })
+	mustPanic("SlidingWindow(slide=-1s)", func() { SlidingWindow(ctx, in, time.Second, -time.Second) })
+	mustPanic("SessionWindow(0)", func() { SessionWindow(ctx, in, 0) })
+	mustPanic("Debounce(-1s)", func() { Debounce(ctx, in, -time.Second) })
+	mustPanic("Sample(0)", func() { Sample(ctx, in, 0) })
+	mustPanic("Throttle(0)", func() { Throttle(ctx, in, 1, 0) })
+	mustPanic("RateLimit(-1s)", func() { RateLimit(ctx, in, -time.Second) })
+}
+
+func TestSessionWindowAndReduce(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan string)
+	go func() {
+		defer close(in)
+		for _, s := range []string{"a", "b", "a"} {
+			in <- s
+		}
+		time.Sleep(50 * time.Millisecond) // 超过 gap：开始新会话
+		in <- "b"
+	}()
+	count := func(acc int, _ string) int { return acc + 1 }
+	got := collect(ReduceByKey(ctx, SessionWindow(ctx, in, 20*time.Millisecond), func(s string) string { return s }, 0, count))
+	if len(got) != 2 || got[0]["a"] != 2 || got[0]["b"] != 1 || got[1]["b"] != 1 || len(got[1]) != 1 {
+		t.Fatalf("sessions = %v", got)
+	}
+}
+
+func TestFlowControl(t *testing.T) {
+	ctx := context.Background()
+
+	start := time.Now()
+	if got := collect(Throttle(ctx, feed(1, 2, 3, 4, 5, 6), 2, 10*time.Millisecond)); len(got) != 6 {
+		t.Fatalf("Throttle = %v", got)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("Throttle let 6 items through in %v, want >= 2 intervals", elapsed)
+	}
+
+	// 连续到达的数据只发出最后一条
+	if got := collect(Debounce(ctx, feed(1, 2, 3), 20*time.Millisecond)); len(got) != 1 || got[0] != 3 {
+		t.Fatalf("Debounce = %v", got)
+	}
+	if got := collect(Sample(ctx, feed(1, 2, 3), time.Hour)); len(got) != 1 || got[0] != 3 {
+		t.Fatalf("Sample = %v", got)
+	}
+	if got := collect(Distinct(ctx, feed(1, 2, 1, 3, 2))); len(got) != 3 || got[2] != 3 {
+		t.Fatalf("Distinct = %v", got)
+	}
+	if got := collect(DistinctUntilChanged(ctx, feed(1, 1, 2, 2, 1))); len(got) != 3 || got[2] != 1 {
+		t.Fatalf("DistinctUntilChanged = %v", got)
+	}
+
+	in := make(chan int)
+	out, errc := Timeout(ctx, in, 20*time.Millisecond)
+	go func() { in <- 1 }()
+	if got := collect(out); len(got) != 1 {
+		t.Fatalf("Timeout forwarded %v", got)
+	}
+	if err := <-errc; !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Timeout error = %v", err)
+	}
+}
+
+// TestThrottleIdleBurst 空闲之后的突发也不能在任意 interval 内放行超过 n 条
+func TestThrottleIdleBurst(t *testing.T) {
+	const (
+		n        = 3
+		interval = 100 * time.Millisecond
+	)
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := 0; i < n; i++ {
+			in <- i
+		}
+		time.Sleep(interval * 3 / 2) // 空闲超过一个周期
+		for i := n; i < 3*n; i++ {
+			in <- i
+		}
+	}()
+	var at []time.Time
+	for range Throttle(context.Background(), in, n, interval) {
+		at = append(at, time.Now())
+	}
+	if len(at) != 3*n {
+		t.Fatalf("got %d items, want %d", len(at), 3*n)
+	}
+	for i := 0; i+n < len(at); i++ {
+		// 留出调度误差
+		if d := at[i+n].Sub(at[i]); d < interval-20*time.Millisecond {
+			t.Fatalf("items %d and %d only %v apart: more than %d items within %v", i, i+n, d, n, interval)
+		}
+	}
+}
+
+// TestThrottleSlowConsumer 下游接收较慢时，按实际接收时刻计算窗口，相邻两条仍至少间隔 interval
+func TestThrottleSlowConsumer(t *testing.T) {
+	const interval = 100 * time.Millisecond
+	out := Throttle(context.Background(), feed(1, 2, 3, 4), 1, interval)
+	var at []time.Time
+	for range out {
+		at = append(at, time.Now())
+		if len(at) == 1 || len(at) == 3 {
+			time.Sleep(interval * 3 / 2) // 慢一拍：下一条阻塞在 emit 上
+		}
+	}
+	if len(at) != 4 {
+		t.Fatalf("got %d items, want 4", len(at))
+	}
+	for i := 1; i < len(at); i++ {
+		if d := at[i].Sub(at[i-1]); d < interval-20*time.Millisecond {
+			t.Fatalf("items %d and %d delivered only %v apart, want >= %v", i-1, i, d, interval)
+		}
+	}
+}
+
+func TestOperators(t *testing.T) {
+	ctx := context.Background()
+	if got := collect(Filter(ctx, feed(1, 2, 3, 4), func(x int) bool { return x%2 == 0 })); len(got) != 2 || got[1] != 4 {
+		t.Fatalf("Filter = %v", got)
+	}
+	if got := collect(Skip(ctx, feed(1, 2, 3), 2)); len(got) != 1 || got[0] != 3 {
+		t.Fatalf("Skip = %v", got)
+	}
+	if got := collect(FlatMap(ctx, feed(1, 2), func(x int) []int { return []int{x, x} })); len(got) != 4 || got[2] != 2 {
+		t.Fatalf("FlatMap = %v", got)
+	}
+	if got := collect(Scan(ctx, feed(1, 2, 3), 0, func(acc, x int) int { return acc + x })); len(got) != 3 || got[2] != 6 {
+		t.Fatalf("Scan = %v", got)
+	}
+	pairs := collect(Zip(ctx, feed(1, 2, 3), feed("a", "b")))
+	if len(pairs) != 2 || pairs[1] != (Pair[int, string]{2, "b"}) {
+		t.Fatalf("Zip = %v", pairs)
+	}
+
+	// TakeWhile 与 Take 一致：条件不满足后立即关闭，不等待上游
+	before := runtime.NumGoroutine()
+	cctx, cancel := context.WithCancel(ctx)
+	if got := collect(TakeWhile(cctx, counter(cctx), func(x int) bool { return x < 5 })); len(got) != 5 {
+		t.Fatalf("TakeWhile = %v", got)
+	}
+	cancel()
+	checkNoLeak(t, before)
+}
+
+func TestSourcesAndSinks(t *testing.T) {
+	ctx := context.Background()
+
+	xs, errc := FromSlice(ctx, []int{1, 2, 3})
+	got, err := ToSlice(ctx, xs)
+	if err != nil || len(got) != 3 || FirstError(errc) != nil {
+		t.Fatalf("FromSlice/ToSlice = %v, %v", got, err)
+	}
+
+	seq, errc := FromIter(ctx, slices.Values([]string{"a", "b"}))
+	var buf bytes.Buffer
+	if err := ToWriter(ctx, &buf, seq, '\n'); err != nil || FirstError(errc) != nil {
+		t.Fatal(err)
+	}
+	lines, errc := FromReader(ctx, strings.NewReader(buf.String()+"tail"), '\n')
+	if got, _ := ToSlice(ctx, lines); len(got) != 3 || got[0] != "a" || got[2] != "tail" {
+		t.Fatalf("FromReader = %q", got)
+	}
+	if err := FirstError(errc); err != nil {
+		t.Fatal(err)
+	}
+
+	readErr := errors.New("read failed")
+	lines, errc = FromReader(ctx, io.MultiReader(strings.NewReader("x\n"), iotest.ErrReader(readErr)), '\n')
+	if got, _ := ToSlice(ctx, lines); len(got) != 1 {
+		t.Fatalf("FromReader before error = %q", got)
+	}
+	if err := FirstError(errc); !errors.Is(err, readErr) {
+		t.Fatalf("FromReader error = %v", err)
+	}
+
+	// 消息队列：ToQueue 写入，Destroy 后 FromQueue 取完剩余消息正常结束
+	q := msgqueue.NewRingMQ(8, "test")
+	msgs, _ := FromSlice(ctx, [][]byte{[]byte("m1"), []byte("m2")})
+	if err := ToQueue(ctx, q, msgs); err != nil {
+		t.Fatal(err)
+	}
+	q.Destroy()
+	fromQ, errc := FromQueue(ctx, q)
+	n := 0
+	if err := ForEach(ctx, fromQ, func([]byte) error { n++; return nil }); err != nil || n != 2 {
+		t.Fatalf("FromQueue delivered %d messages, err %v", n, err)
+	}
+	if err := FirstError(errc); err != nil {
+		t.Fatalf("FromQueue error = %v", err)
+	}
+
+	// ctx 在 FromQueue 阻塞于空队列的 Deq 时结束：报告 ctx.Err() 而不是正常结束
+	live := msgqueue.NewRingMQ(8, "live")
+	qctx, qcancel := context.WithCancel(ctx)
+	fromLive, errc := FromQueue(qctx, live)
+	time.AfterFunc(10*time.Millisecond, qcancel)
+	for range fromLive {
+	}
+	if err := FirstError(errc); !errors.Is(err, context.Canceled) {
+		t.Fatalf("FromQueue after cancel error = %v, want context.Canceled", err)
+	}
+
+	// 队列已满时 ToQueue 等待消费者腾出空间，不丢消息也不报错
+	small := msgqueue.NewRingMQ(2, "small")
+	many := make([][]byte, 20)
+	for i := range many {
+		many[i] = []byte{byte(i)}
+	}
+	msgs, _ = FromSlice(ctx, many)
+	toErr := make(chan error, 1)
+	go func() {
+		toErr <- ToQueue(ctx, small, msgs)
+		small.Destroy()
+	}()
+	fromSmall, errc := FromQueue(ctx, small)
+	recv, err := ToSlice(ctx, fromSmall)
+	if err != nil || FirstError(errc) != nil || <-toErr != nil {
+		t.Fatalf("ToQueue with backpressure failed: %v", err)
+	}
+	if len(recv) != len(many) || recv[19][0] != 19 {
+		t.Fatalf("ToQueue delivered %d messages, want %d", len(recv), len(many))
+	}
+
+	stop := errors.New("stop")
+	cctx, cancel := context.WithCancel(ctx)
+	ticks, errc := FromTicker(cctx, time.Millisecond)
+	count := 0
+	err = ForEach(ctx, ticks, func(time.Time) error {
+		if count++; count == 3 {
+			return stop
+		}
+		return nil
+	})
+	cancel()
+	if !errors.Is(err, stop) || FirstError(errc) != nil {
+		t.Fatalf("ForEach = %v", err)
+	}
+
+	// 下游放弃后取消 ctx，数据源报告 ctx.Err()
+	cctx, cancel = context.WithCancel(ctx)
+	nums, errc := FromIter(cctx, func(yield func(int) bool) {
+		for i := 0; yield(i); i++ {
+		}
+	})
+	<-nums
+	cancel()
+	if err := FirstError(errc); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled source error = %v", err)
+	}
+}
+
+// runningSum 有状态阶段：输出每个数及到目前为止的累加和；crashAt 为非0时处理到该数据返回错误，模拟崩溃
+type runningSum struct {
+	sum     int
+	crashAt int
+}
+
+func (s *runningSum) Process(x int) ([]string, error) {
+	if x == s.crashAt {
+		return nil, errors.New("crash")
+	}
+	s.sum += x
+	return []string{fmt.Sprintf("%d:%d", x, s.sum)}, nil
+}
+
+func (s *runningSum) Snapshot() ([]byte, error) { return json.Marshal(s.sum) }
+
+func (s *runningSum) Restore(b []byte) error { return json.Unmarshal(b, &s.sum) }
+
+func TestCheckpointedExactlyOnce(t *testing.T) {
+	dir := t.TempDir()
+	cfg := CheckpointConfig{
+		Interval: 10,
+		Store:    NewFileCheckpointStore(filepath.Join(dir, "checkpoint.json")),
+		SinkPath: filepath.Join(dir, "out.txt"),
+	}
+	src := Replayable[int](func(offset int64) iter.Seq[int] {
+		return func(yield func(int) bool) {
+			for i := int(offset) + 1; i <= 95; i++ {
+				if !yield(i) {
+					return
+				}
+			}
+		}
+	})
+	encode := func(s string) []byte { return []byte(s + "\n") }
+
+	var want strings.Builder
+	for i, sum := 1, 0; i <= 95; i++ {
+		sum += i
+		fmt.Fprintf(&want, "%d:%d\n", i, sum)
+	}
+
+	// 第一次运行在第 55 条数据处崩溃：检查点最多到第 50 条（取决于终止与最后一次确认的先后），之后写入的输出未提交
+	if err := RunCheckpointed(context.Background(), cfg, src, &runningSum{crashAt: 55}, encode); err == nil {
+		t.Fatal("expected crash")
+	}
+	cp, err := cfg.Store.Load()
+	if err != nil || (cp != nil && (cp.Offset > 50 || cp.Offset%10 != 0 || cp.Epoch != cp.Offset/10)) {
+		t.Fatalf("checkpoint after crash = %+v, %v", cp, err)
+	}
+
+	// 第二次运行被取消，同样相当于崩溃
+	ctx, cancel := context.WithCancel(context.Background())
+	slow := Replayable[int](func(offset int64) iter.Seq[int] {
+		return func(yield func(int) bool) {
+			for x := range src(offset) {
+				if x == 72 {
+					cancel()
+				}
+				if !yield(x) {
+					return
+				}
+			}
+		}
+	})
+	if err := RunCheckpointed(ctx, cfg, slow, &runningSum{}, encode); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled run = %v", err)
+	}
+
+	// 恢复后运行完成：输出与一次性运行完全相同，没有重复
+	if err := RunCheckpointed(context.Background(), cfg, src, &runningSum{}, encode); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(cfg.SinkPath)
+	if string(got) != want.String() {
+		t.Fatalf("output mismatch:\n%s", got)
+	}
+	if cp, _ := cfg.Store.Load(); cp.Offset != 95 {
+		t.Fatalf("final checkpoint = %+v", cp)
+	}
+
+	// 已完成的流水线再次运行不会产生新输出
+	if err := RunCheckpointed(context.Background(), cfg, src, &runningSum{}, encode); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := os.ReadFile(cfg.SinkPath); string(again) != want.String() {
+		t.Fatal("rerun changed output")
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(dir, "*.tmp*")); len(tmps) != 0 {
+		t.Fatalf("leftover temp files %v", tmps)
+	}
+}
+
+// prefixSum 有状态阶段：输出到目前为止的累加和
+type prefixSum struct{ sum int }
+
+func (s *prefixSum) Process(x int) ([]int, error) {
+	s.sum += x
+	return []int{s.sum}, nil
+}
+
+func (s *prefixSum) Snapshot() ([]byte, error) { return json.Marshal(s.sum) }
+
+func (s *prefixSum) Restore(b []byte) error { return json.Unmarshal(b, &s.sum) }
+
+// lineNumber 有状态阶段：给每条数据加上行号；crashAt 为非0时处理到该行返回错误
+type lineNumber struct {
+	n       int
+	crashAt int
+}
+
+func (s *lineNumber) Process(x int) ([]string, error) {
+	if s.n+1 == s.crashAt {
+		return nil, errors.New("crash")
+	}
+	s.n++
+	return []string{fmt.Sprintf("%d:%d", s.n, x)}, nil
+}
+
+func (s *lineNumber) Snapshot() ([]byte, error) { return json.Marshal(s.n) }
+
+func (s *lineNumber) Restore(b []byte) error { return json.Unmarshal(b, &s.n) }
+
+func TestCheckpointedChain(t *testing.T) {
+	before := runtime.NumGoroutine()
+	dir := t.TempDir()
+	cfg := CheckpointConfig{
+		Interval: 7,
+		Store:    NewFileCheckpointStore(filepath.Join(dir, "checkpoint.json")),
+		SinkPath: filepath.Join(dir, "out.txt"),
+	}
+	src := Replayable[int](func(offset int64) iter.Seq[int] {
+		return func(yield func(int) bool) {
+			for i := int(offset) + 1; i <= 60; i++ {
+				if !yield(i) {
+					return
+				}
+			}
+		}
+	})
+	encode := func(y any) []byte { return []byte(y.(string) + "\n") }
+	stages := func(crashAt int) []CheckpointStage {
+		return []CheckpointStage{EraseStage[int, int](&prefixSum{}), EraseStage[int, string](&lineNumber{crashAt: crashAt})}
+	}
+
+	var want strings.Builder
+	for i, sum := 1, 0; i <= 60; i++ {
+		sum += i
+		fmt.Fprintf(&want, "%d:%d\n", i, sum)
+	}
+
+	// 第二个阶段在第 40 行崩溃：检查点中每个阶段各有一份状态
+	var se *StageError
+	if err := RunCheckpointedChain(context.Background(), cfg, src, stages(40), encode); !errors.As(err, &se) || se.Stage != "checkpointed[1]" {
+		t.Fatalf("crashed run = %v, want StageError from stage 1", err)
+	}
+	checkNoLeak(t, before) // 放弃的周期不会留下等待中的 goroutine
+	cp, err := cfg.Store.Load()
+	if err != nil || cp == nil || len(cp.States) != 2 || cp.Offset > 35 || cp.Offset%7 != 0 {
+		t.Fatalf("checkpoint after crash = %+v, %v", cp, err)
+	}
+
+	// 阶段数与检查点不符时拒绝恢复
+	if err := RunCheckpointedChain(context.Background(), cfg, src, stages(0)[:1], encode); err == nil {
+		t.Fatal("expected error for mismatched stage count")
+	}
+
+	if err := RunCheckpointedChain(context.Background(), cfg, src, stages(0), encode); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(cfg.SinkPath); string(got) != want.String() {
+		t.Fatalf("output mismatch:\n%s", got)
+	}
+	if cp, _ := cfg.Store.Load(); cp.Offset != 60 {
+		t.Fatalf("final checkpoint = %+v", cp)
+	}
+}
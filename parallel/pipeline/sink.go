@@ -0,0 +1,82 @@
+package pipeline
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"time"
+
+	msgqueue "github.com/leoxiang66/go-patterns/container/msgQueue"
+)
+
+// 终点：阻塞直到输入通道关闭、出错或 ctx 结束，返回导致结束的错误（正常结束返回nil，ctx 结束返回 ctx.Err()）
+// 出错或 ctx 结束时不再读取输入，上游应随 ctx 一起取消
+
+// ForEach 对每条数据调用 fn，fn 返回错误时停止并返回该错误
+func ForEach[X any](ctx context.Context, in <-chan X, fn func(x X) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case x, ok := <-in:
+			if !ok {
+				return nil
+			}
+			if err := fn(x); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ToSlice 收集所有数据；出错时同时返回已收集的部分
+func ToSlice[X any](ctx context.Context, in <-chan X) ([]X, error) {
+	var out []X
+	err := ForEach(ctx, in, func(x X) error {
+		out = append(out, x)
+		return nil
+	})
+	return out, err
+}
+
+// ToWriter 把每条数据写入 w，每条之后追加分隔符 delim（与 FromReader 对应）；结束前刷新缓冲
+func ToWriter[X ~string | ~[]byte](ctx context.Context, w io.Writer, in <-chan X, delim byte) error {
+	bw := bufio.NewWriter(w)
+	err := ForEach(ctx, in, func(x X) error {
+		if _, err := bw.Write([]byte(x)); err != nil {
+			return err
+		}
+		return bw.WriteByte(delim)
+	})
+	if ferr := bw.Flush(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// ToQueue 把每条消息放入消息队列，不丢弃消息：队列已满时退避重试（等待时不再读取输入，对上游形成背压），
+// 队列已销毁时停止并返回 Enq 的错误
+func ToQueue(ctx context.Context, q msgqueue.MessageQueueInterface, in <-chan []byte) error {
+	const (
+		minBackoff = time.Millisecond
+		maxBackoff = 100 * time.Millisecond
+	)
+	timer := time.NewTimer(0)
+	timer.Stop()
+	defer timer.Stop()
+	return ForEach(ctx, in, func(msg []byte) error {
+		for backoff := minBackoff; ; backoff = min(2*backoff, maxBackoff) {
+			err := q.Enq(msg)
+			if err == nil || !q.IsLive() {
+				return err
+			}
+			// 队列存活时 Enq 失败只可能是已满，等待消费者取走消息后重试
+			timer.Reset(backoff)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+	})
+}
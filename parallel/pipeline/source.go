@@ -0,0 +1,145 @@
+package pipeline
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"iter"
+	"time"
+
+	msgqueue "github.com/leoxiang66/go-patterns/container/msgQueue"
+)
+
+// 数据源：返回数据通道和错误通道。数据发送完毕（或出错、ctx 结束）后先关闭数据通道，
+// 再向错误通道发送至多一个错误并关闭它，因此读到错误通道关闭即表示数据源已结束；
+// ctx 结束导致的提前退出报告 ctx.Err()
+
+// source 启动数据源 goroutine：gen 通过 send 发送数据，返回值作为数据源的错误
+func source[X any](ctx context.Context, gen func(send func(X) bool) error) (chan X, <-chan error) {
+	out := make(chan X)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		stopped := false
+		err := gen(func(x X) bool {
+			stopped = !emit(ctx, out, x)
+			return !stopped
+		})
+		if err == nil && stopped {
+			err = ctx.Err() // send 失败只可能是 ctx 结束
+		}
+		close(out)
+		if err != nil {
+			errc <- err
+		}
+	}()
+	return out, errc
+}
+
+// FromSlice 依次发送 xs 中的数据
+func FromSlice[X any](ctx context.Context, xs []X) (chan X, <-chan error) {
+	return source(ctx, func(send func(X) bool) error {
+		for _, x := range xs {
+			if !send(x) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// FromIter 依次发送迭代器产生的数据，ctx 结束时停止迭代
+func FromIter[X any](ctx context.Context, seq iter.Seq[X]) (chan X, <-chan error) {
+	return source(ctx, func(send func(X) bool) error {
+		for x := range seq {
+			if !send(x) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// FromReader 按分隔符 delim 切分 r 的内容，逐段发送（不含分隔符）；末尾没有分隔符的最后一段也会发送
+// 读取 r 本身不响应 ctx，需要中断阻塞的读取时应由调用方关闭 r
+func FromReader(ctx context.Context, r io.Reader, delim byte) (chan string, <-chan error) {
+	return source(ctx, func(send func(string) bool) error {
+		br := bufio.NewReader(r)
+		for {
+			s, err := br.ReadString(delim)
+			if len(s) > 0 {
+				if s[len(s)-1] == delim {
+					s = s[:len(s)-1]
+				}
+				if !send(s) {
+					return nil
+				}
+			}
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// FromTicker 每隔 interval 发送当前时间，直到 ctx 结束；这是它唯一的结束方式，因此不报告 ctx.Err()
+// 下游来不及接收时会跳过错过的时刻（与 time.Ticker 一致）
+func FromTicker(ctx context.Context, interval time.Duration) (chan time.Time, <-chan error) {
+	out := make(chan time.Time)
+	errc := make(chan error)
+	go func() {
+		defer close(errc)
+		defer close(out)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case t := <-ticker.C:
+				if !emit(ctx, out, t) {
+					return
+				}
+			}
+		}
+	}()
+	return out, errc
+}
+
+// FromQueue 不断从消息队列中取出消息并发送；队列被销毁（Destroy）且取空后正常结束，
+// ctx 结束（包括阻塞在 Deq 时）报告 ctx.Err()
+func FromQueue(ctx context.Context, q msgqueue.MessageQueueInterface) (chan []byte, <-chan error) {
+	return source(ctx, func(send func([]byte) bool) error {
+		for {
+			msg, err := q.Deq(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if !q.IsLive() {
+					return nil
+				}
+				return err
+			}
+			if !send(msg) {
+				return nil
+			}
+		}
+	})
+}
+
+// FirstError 等待所有错误通道关闭，返回其中第一个非nil错误（按参数顺序）
+func FirstError(errcs ...<-chan error) error {
+	var first error
+	for _, errc := range errcs {
+		for err := range errc {
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
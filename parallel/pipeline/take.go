@@ -11,6 +11,9 @@ func Take[X any](q <-chan struct{}, n int, in <-chan X) chan X {
 
 	go func() {
 		defer close(out) // 确保输出通道最终关闭
+		if n <= 0 {
+			return
+		}
 		for {
 			select {
 			case <-q: // 响应终止信号，提前退出
@@ -28,6 +31,9 @@ func Take[X any](q <-chan struct{}, n int, in <-chan X) chan X {
 					return
 				case out <- x:
 					count++ // 计数+1
+					if count >= n { // 截取够n个数据后立即退出，不再等待上游
+						return
+					}
 				}
 			}
 		}
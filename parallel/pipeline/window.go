@@ -0,0 +1,260 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+)
+
+// 窗口阶段：把输入切分为一批批数据（[]X）发出；输入关闭时先发出尚未结束的窗口再关闭输出，ctx 结束时直接退出
+// 空窗口不会发出
+
+// emit 在 ctx 结束前发送 v
+func emit[X any](ctx context.Context, out chan<- X, v X) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case out <- v:
+		return true
+	}
+}
+
+// Batch 按数量攒批：攒满 n 条，或距本批第一条数据已过 maxWait（maxWait<=0 表示不限时）时发出
+// 参数：
+//
+//	ctx: 控制终止
+//	in: 输入通道
+//	n: 每批最多条数，小于1时按1处理
+//	maxWait: 每批最长等待时间
+//
+// 返回：
+//
+//	输出通道（每次发送一批数据）
+func Batch[X any](ctx context.Context, in <-chan X, n int, maxWait time.Duration) chan []X {
+	n = max(1, n)
+	out := make(chan []X)
+	go func() {
+		defer close(out)
+		var (
+			batch []X
+			timer *time.Timer
+			timeC <-chan time.Time // 为 nil 时不触发
+		)
+		flush := func() bool {
+			if timer != nil {
+				timer.Stop()
+				timeC = nil
+			}
+			if len(batch) == 0 {
+				return true
+			}
+			b := batch
+			batch = nil
+			return emit(ctx, out, b)
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timeC:
+				timeC = nil
+				if !flush() {
+					return
+				}
+			case x, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				batch = append(batch, x)
+				if len(batch) == 1 && maxWait > 0 {
+					if timer == nil {
+						timer = time.NewTimer(maxWait)
+					} else {
+						timer.Reset(maxWait)
+					}
+					timeC = timer.C
+				}
+				if len(batch) >= n && !flush() {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// TumblingWindow 滚动窗口：每隔 size 发出这段时间内到达的数据，窗口互不重叠
+func TumblingWindow[X any](ctx context.Context, in <-chan X, size time.Duration) chan []X {
+	if size <= 0 {
+		panic("TumblingWindow: size 必须大于0")
+	}
+	out := make(chan []X)
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(size)
+		defer ticker.Stop()
+		var window []X
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if len(window) > 0 {
+					w := window
+					window = nil
+					if !emit(ctx, out, w) {
+						return
+					}
+				}
+			case x, ok := <-in:
+				if !ok {
+					if len(window) > 0 {
+						emit(ctx, out, window)
+					}
+					return
+				}
+				window = append(window, x)
+			}
+		}
+	}()
+	return out
+}
+
+// stamped 带到达时间的数据
+type stamped[X any] struct {
+	at time.Time
+	v  X
+}
+
+// SlidingWindow 滑动窗口：每隔 slide 发出最近 size 时间内到达的数据，相邻窗口可以重叠（slide < size）
+// 输入关闭时发出最后一个窗口
+func SlidingWindow[X any](ctx context.Context, in <-chan X, size, slide time.Duration) chan []X {
+	if size <= 0 || slide <= 0 {
+		panic("SlidingWindow: size 和 slide 必须大于0")
+	}
+	out := make(chan []X)
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(slide)
+		defer ticker.Stop()
+		var buf []stamped[X]
+		// window 淘汰早于 now-size 的数据，返回窗口内数据的副本
+		window := func(now time.Time) []X {
+			i := 0
+			for i < len(buf) && !buf[i].at.After(now.Add(-size)) {
+				i++
+			}
+			buf = append(buf[:0], buf[i:]...)
+			w := make([]X, len(buf))
+			for j, s := range buf {
+				w[j] = s.v
+			}
+			return w
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if w := window(now); len(w) > 0 && !emit(ctx, out, w) {
+					return
+				}
+			case x, ok := <-in:
+				if !ok {
+					if w := window(time.Now()); len(w) > 0 {
+						emit(ctx, out, w)
+					}
+					return
+				}
+				buf = append(buf, stamped[X]{at: time.Now(), v: x})
+			}
+		}
+	}()
+	return out
+}
+
+// SessionWindow 会话窗口：相邻数据间隔不超过 gap 的归为同一会话，超过 gap 没有新数据时发出该会话
+func SessionWindow[X any](ctx context.Context, in <-chan X, gap time.Duration) chan []X {
+	if gap <= 0 {
+		panic("SessionWindow: gap 必须大于0")
+	}
+	out := make(chan []X)
+	go func() {
+		defer close(out)
+		timer := time.NewTimer(gap)
+		timer.Stop()
+		defer timer.Stop()
+		var session []X
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+				s := session
+				session = nil
+				if !emit(ctx, out, s) {
+					return
+				}
+			case x, ok := <-in:
+				if !ok {
+					if len(session) > 0 {
+						emit(ctx, out, session)
+					}
+					return
+				}
+				session = append(session, x)
+				timer.Reset(gap) // 每条新数据都延长会话
+			}
+		}
+	}()
+	return out
+}
+
+// ReduceByKey 按 key 聚合每个窗口：对窗口内每条数据以 init 为初值调用 reduce，每个窗口发出一个结果 map
+// init 会按值复制给每个 key，A 为切片或 map 等引用类型时应在 reduce 中自行分配
+// 参数：
+//
+//	ctx: 控制终止
+//	in: 窗口通道（Batch、TumblingWindow、SlidingWindow、SessionWindow 的输出）
+//	key: 分组函数
+//	init: 每个 key 的聚合初值
+//	reduce: 聚合函数
+//
+// 返回：
+//
+//	输出通道（每个窗口对应一个 key → 聚合结果）
+func ReduceByKey[X any, K comparable, A any](
+	ctx context.Context,
+	in <-chan []X,
+	key func(x X) K,
+	init A,
+	reduce func(acc A, x X) A,
+) chan map[K]A {
+	out := make(chan map[K]A)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case w, ok := <-in:
+				if !ok {
+					return
+				}
+				agg := make(map[K]A)
+				for _, x := range w {
+					k := key(x)
+					acc, seen := agg[k]
+					if !seen {
+						acc = init
+					}
+					agg[k] = reduce(acc, x)
+				}
+				if !emit(ctx, out, agg) {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}